@@ -0,0 +1,226 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"rest-article/database/model"
+	"rest-article/policy"
+	"strconv"
+	"time"
+)
+
+type DeletedArticle struct {
+	Id        string `json:"id" xml:"id" yaml:"id"`
+	Title     string `json:"title" xml:"title" yaml:"title"`
+	Date      string `json:"date" xml:"date" yaml:"date"`
+	DeletedAt string `json:"deleted_at" xml:"deleted_at" yaml:"deleted_at"`
+}
+
+type DeletedArticlesResponse struct {
+	XMLName  xml.Name         `json:"-" xml:"deleted_articles" yaml:"-"`
+	Count    int              `json:"count" xml:"count" yaml:"count"`
+	Articles []DeletedArticle `json:"articles" xml:"article" yaml:"articles"`
+}
+
+type ActionResponse struct {
+	XMLName xml.Name `json:"-" xml:"action" yaml:"-"`
+	Success bool     `json:"success" xml:"success" yaml:"success"`
+	Id      string   `json:"id" xml:"id" yaml:"id"`
+}
+
+func (app *App) deleteArticleFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, ActionResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	articleID, _ := strconv.Atoi(id)
+	if !app.permitArticle(w, r, policy.ActionDelete, articleID) {
+		return
+	}
+
+	err := app.repo.DeleteArticle(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	if err := app.index.Remove(articleID); err != nil {
+		app.logger.Errorf("error removing article %s from index because: %v", id, err)
+	}
+
+	app.writeActionResponse(w, r, id)
+}
+
+func (app *App) getAdminArticleFunction(w http.ResponseWriter, r *http.Request) {
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	response, article, err := app.adminArticle(id, func(date time.Time) string { return articleDate(r, date) }, loc)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	app.writeArticleResponse(w, r, response, article)
+}
+
+// AdminArticle returns the article whatever its status as served by the v2
+// admin endpoint, with timestamps in UTC. sql.ErrNoRows is returned when there
+// is no such article.
+func (app *App) AdminArticle(id string) (Article, error) {
+	response, _, err := app.adminArticle(id, formatDate, time.UTC)
+	return response, err
+}
+
+// adminArticle loads the article whatever its status along with its tags and
+// authors, date formats its date and loc its timestamps.
+func (app *App) adminArticle(id string, date func(time.Time) string, loc *time.Location) (Article, *model.Article, error) {
+
+	article, tags, err := app.repo.GetAdminArticleByID(id)
+	if err != nil {
+		return Article{}, nil, err
+	}
+
+	var tagsList []string
+	for _, tag := range tags {
+		tagsList = append(tagsList, tag.Name)
+	}
+
+	authors, err := app.repo.GetArticleAuthors([]int{article.Id})
+	if err != nil {
+		return Article{}, nil, err
+	}
+
+	response := Article{
+		Id:      fmt.Sprintf("%d", article.Id),
+		Title:   article.Title,
+		Date:    date(article.Date),
+		Body:    article.Body,
+		Tags:    tagsList,
+		Status:  article.Status,
+		Authors: authorResponses(authors[article.Id]),
+	}
+	if article.PublishAt != nil {
+		response.PublishAt = formatTimestamp(*article.PublishAt, loc)
+	}
+
+	return response, article, nil
+}
+
+func (app *App) getDeletedArticlesFunction(w http.ResponseWriter, r *http.Request) {
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	articles, err := app.repo.GetDeletedArticles()
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := DeletedArticlesResponse{
+		Count:    len(articles),
+		Articles: []DeletedArticle{},
+	}
+	for _, article := range articles {
+		deleted := DeletedArticle{
+			Id:    fmt.Sprintf("%d", article.Id),
+			Title: article.Title,
+			Date:  articleDate(r, article.Date),
+		}
+		if article.DeletedAt != nil {
+			deleted.DeletedAt = formatTimestamp(*article.DeletedAt, loc)
+		}
+		response.Articles = append(response.Articles, deleted)
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) restoreArticleFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, ActionResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.repo.RestoreArticle(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "deleted article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("deleted article not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	article, tags, err := app.repo.GetAdminArticleByID(id)
+	if err != nil {
+		app.logger.Errorf("error loading restored article %s for indexing because: %v", id, err)
+	} else {
+		var tagsList []string
+		for _, tag := range tags {
+			tagsList = append(tagsList, tag.Name)
+		}
+		app.syncIndex(*article, tagsList)
+	}
+
+	app.writeActionResponse(w, r, id)
+}
+
+func (app *App) writeActionResponse(w http.ResponseWriter, r *http.Request, id string) {
+	response := ActionResponse{
+		Success: true,
+		Id:      id,
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/database/model"
+	"rest-article/search"
+	"testing"
+	"time"
+)
+
+func TestDeleteArticleFunction(t *testing.T) {
+	index := search.NewMemoryIndex()
+	_ = index.Index(model.Article{
+		Id:    1,
+		Title: "test article",
+		Date:  time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Body:  "test article",
+	}, []string{"test"})
+
+	app := newTestApp("TestDeleteArticleFunction", withIndex(index))
+
+	req := httptest.NewRequest(http.MethodDelete, "/articles/1", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ActionResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, true, respBody.Success)
+
+	results, _ := index.Search(search.Query{Text: "test"})
+	assert.Len(t, results, 0)
+}
+
+func TestGetDeletedArticlesFunction(t *testing.T) {
+	app := newTestApp("TestGetDeletedArticlesFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/articles/deleted", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody DeletedArticlesResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 1, respBody.Count)
+	assert.Equal(t, "2020-03-01T00:00:00Z", respBody.Articles[0].DeletedAt)
+}
+
+func TestRestoreArticleFunction(t *testing.T) {
+	app := newTestApp("TestRestoreArticleFunction")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/articles/1/restore", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	results, _ := app.index.Search(search.Query{Text: "test"})
+	assert.Len(t, results, 1)
+}
+
+func TestGetAdminArticleFunction(t *testing.T) {
+	app := newTestApp("TestGetAdminArticleFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/articles/2", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody Article
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, model.StatusScheduled, respBody.Status)
+	assert.Equal(t, "2020-03-01T09:00:00Z", respBody.PublishAt)
+}
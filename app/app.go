@@ -4,37 +4,66 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"rest-article/auth"
 	"rest-article/database/model"
 	"rest-article/log"
+	"rest-article/policy"
 	"rest-article/repo"
+	"rest-article/search"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// maxListLimit caps the number of articles a single listing can return
+const maxListLimit = 100
+
 // Header type constants
 const (
 	HeaderContentType = "Content-Type"
+	HeaderAuthor      = "X-Author"
 	ContentTypeJSON   = "application/json"
 )
 
+// logger reports the failures of helpers that do not belong to an App
+var logger = log.NewLogger().WithField("module", "app")
+
 type App struct {
-	ctx      context.Context
-	Router   *mux.Router
-	Database *sql.DB
-	repo     repo.Repo
-	logger   *logrus.Entry
+	ctx           context.Context
+	Router        *mux.Router
+	Database      *sql.DB
+	repo          repo.Repo
+	index         search.Index
+	authenticator *auth.Authenticator
+	runtime       runtimeValue
+	// readYourWrites is set before serving, nil leaves reads unpinned
+	readYourWrites *ReadYourWrites
+	logger         *logrus.Entry
 }
 
 type Article struct {
-	Id    string   `json:"id"`
-	Title string   `json:"title"`
-	Date  string   `json:"date"`
-	Body  string   `json:"body"`
-	Tags  []string `json:"tags"`
+	XMLName   xml.Name `json:"-" xml:"article" yaml:"-"`
+	Id        string   `json:"id" xml:"id" yaml:"id"`
+	Title     string   `json:"title" xml:"title" yaml:"title"`
+	Date      string   `json:"date" xml:"date" yaml:"date"`
+	Body      string   `json:"body" xml:"body" yaml:"body"`
+	Tags      []string `json:"tags" xml:"tags>tag" yaml:"tags"`
+	Status    string   `json:"status,omitempty" xml:"status,omitempty" yaml:"status,omitempty"`
+	PublishAt string   `json:"publish_at,omitempty" xml:"publish_at,omitempty" yaml:"publish_at,omitempty"`
+	AuthorIds []string `json:"author_ids,omitempty" xml:"author_ids>id,omitempty" yaml:"author_ids,omitempty"`
+	Authors   []Author `json:"authors,omitempty" xml:"authors>author,omitempty" yaml:"authors,omitempty"`
+}
+
+type ArticlesResponse struct {
+	XMLName  xml.Name  `json:"-" xml:"articles" yaml:"-"`
+	Count    int       `json:"count" xml:"count" yaml:"count"`
+	Articles []Article `json:"articles" xml:"article" yaml:"articles"`
 }
 
 type PostArticleRequest struct {
@@ -42,8 +71,9 @@ type PostArticleRequest struct {
 }
 
 type CreateArticleResponse struct {
-	Success bool `json:"success"`
-	Id      int  `json:"id"`
+	XMLName xml.Name `json:"-" xml:"created" yaml:"-"`
+	Success bool     `json:"success" xml:"success" yaml:"success"`
+	Id      int      `json:"id" xml:"id" yaml:"id"`
 }
 
 type TagsResponse struct {
@@ -58,10 +88,21 @@ type TagsResponse struct {
 }
 
 type TagSummaryResponse struct {
-	Tag         string   `json:"tag"`
-	Count       int      `json:"count"`
-	Articles    []string `json:"articles"`
-	RelatedTags []string `json:"related_tag"`
+	XMLName     xml.Name `json:"-" xml:"tag_summary" yaml:"-"`
+	Tag         string   `json:"tag" xml:"tag" yaml:"tag"`
+	Count       int      `json:"count" xml:"count" yaml:"count"`
+	Articles    []string `json:"articles" xml:"articles>id" yaml:"articles"`
+	RelatedTags []string `json:"related_tags" xml:"related_tags>tag" yaml:"related_tags"`
+}
+
+// TagSummaryResponseV1 is the tag summary of v1, which names the related tags
+// related_tag.
+type TagSummaryResponseV1 struct {
+	XMLName     xml.Name `json:"-" xml:"tag_summary" yaml:"-"`
+	Tag         string   `json:"tag" xml:"tag" yaml:"tag"`
+	Count       int      `json:"count" xml:"count" yaml:"count"`
+	Articles    []string `json:"articles" xml:"articles>id" yaml:"articles"`
+	RelatedTags []string `json:"related_tag" xml:"related_tag>tag" yaml:"related_tag"`
 }
 
 type ErrorResponse struct {
@@ -72,37 +113,215 @@ type ErrorResponse struct {
 
 type ResponseError string
 
-func NewApp(router *mux.Router, database *sql.DB, ctx context.Context) *App {
+// NewApp returns the application, a nil authenticator leaves every route open.
+func NewApp(router *mux.Router, database *sql.DB, index search.Index, authenticator *auth.Authenticator, ctx context.Context) *App {
+
+	app := NewAppWithRepo(router, repo.NewArticleRepo(ctx, database), index, authenticator, ctx)
+	app.Database = database
+
+	return app
+}
+
+// NewAppWithRepo returns the application serving the articles of articleRepo,
+// it lets the API run on top of another store such as repo.ArticleRepoMock.
+func NewAppWithRepo(router *mux.Router, articleRepo repo.Repo, index search.Index, authenticator *auth.Authenticator, ctx context.Context) *App {
 
 	return &App{
-		Router:   router,
-		Database: database,
-		ctx:      ctx,
-		repo:     repo.NewArticleRepo(ctx, database),
-		logger:   log.NewLogger().WithContext(ctx).WithField("module", "app"),
+		Router:        router,
+		ctx:           ctx,
+		repo:          articleRepo,
+		index:         index,
+		authenticator: authenticator,
+		logger:        log.NewLogger().WithContext(ctx).WithField("module", "app"),
 	}
 }
 
 func (app *App) SetupRouter() {
+	// an app built without an index searches an in process one
+	if app.index == nil {
+		app.index = search.NewMemoryIndex()
+	}
+
+	app.Router.Use(app.compress)
+	app.Router.Use(app.corsHeaders)
+	app.Router.Use(app.identify)
+	app.Router.Use(app.rateLimit)
+	app.Router.Use(app.pinReads)
+
+	app.setupRoutes(app.Router.PathPrefix("/v1").Subrouter(), app.withVersion(Version1))
+	app.setupRoutes(app.Router.PathPrefix("/v2").Subrouter(), app.withVersion(Version2))
+	app.setupRoutes(app.Router, app.negotiateVersion)
+
 	app.Router.
 		Methods("GET").
-		Path("/articles/{id}").
-		HandlerFunc(app.getArticleFunction)
+		Path("/ping").
+		HandlerFunc(app.pingFunction)
+
+	app.Router.
+		Methods("GET").
+		Path("/openapi.json").
+		HandlerFunc(app.openAPIFunction)
+
+	app.Router.
+		Methods("GET").
+		Path("/feeds/articles.atom").
+		HandlerFunc(app.authorize(auth.ScopeArticlesRead, app.feedFunction(FeedAtom)))
+
+	app.Router.
+		Methods("GET").
+		Path("/feeds/articles.rss").
+		HandlerFunc(app.authorize(auth.ScopeArticlesRead, app.feedFunction(FeedRSS)))
 
 	app.Router.
+		Methods("GET").
+		Path("/feeds/tag/{tagName}.atom").
+		HandlerFunc(app.authorize(auth.ScopeArticlesRead, app.feedFunction(FeedAtom)))
+
+	app.Router.
+		Methods("GET").
+		Path("/feeds/tag/{tagName}.rss").
+		HandlerFunc(app.authorize(auth.ScopeArticlesRead, app.feedFunction(FeedRSS)))
+
+	app.setupPreflight()
+}
+
+// setupRoutes registers the versioned routes of the API on router, version
+// picks the API version of every request.
+func (app *App) setupRoutes(router *mux.Router, version mux.MiddlewareFunc) {
+	router.
+		Methods("GET").
+		Path("/articles/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getArticleFunction)))
+
+	router.
+		Methods("GET").
+		Path("/articles").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getArticlesFunction)))
+
+	router.
 		Methods("POST").
 		Path("/articles").
-		HandlerFunc(app.postArticleFunction)
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.postArticleFunction)))
 
-	app.Router.
+	router.
+		Methods("POST").
+		Path("/articles:batch").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.postArticlesBatchFunction)))
+
+	router.
+		Methods("PUT").
+		Path("/articles/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.putArticleFunction)))
+
+	router.
+		Methods("DELETE").
+		Path("/articles/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.deleteArticleFunction)))
+
+	router.
+		Methods("GET").
+		Path("/articles/{id}/revisions").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getRevisionsFunction)))
+
+	router.
+		Methods("GET").
+		Path("/articles/{id}/revisions/{revision}").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getRevisionFunction)))
+
+	router.
+		Methods("GET").
+		Path("/articles/{id}/revisions/{from}/diff/{to}").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getRevisionDiffFunction)))
+
+	router.
+		Methods("POST").
+		Path("/articles/{id}/revisions/{revision}/restore").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.restoreRevisionFunction)))
+
+	router.
 		Methods("GET").
 		Path("/tag/{tagName}/{date}").
-		HandlerFunc(app.getTagsFunction)
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getTagsFunction)))
 
-	app.Router.
+	router.
 		Methods("GET").
-		Path("/ping").
-		HandlerFunc(app.pingFunction)
+		Path("/authors").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getAuthorsFunction)))
+
+	router.
+		Methods("POST").
+		Path("/authors").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.postAuthorFunction)))
+
+	router.
+		Methods("GET").
+		Path("/authors/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getAuthorFunction)))
+
+	router.
+		Methods("PUT").
+		Path("/authors/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.putAuthorFunction)))
+
+	router.
+		Methods("DELETE").
+		Path("/authors/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesWrite, app.deleteAuthorFunction)))
+
+	router.
+		Methods("GET").
+		Path("/authors/{id}/articles").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.getAuthorArticlesFunction)))
+
+	router.
+		Methods("GET").
+		Path("/search").
+		Handler(version(app.authorize(auth.ScopeArticlesRead, app.searchFunction)))
+
+	router.
+		Methods("GET").
+		Path("/admin/articles/deleted").
+		Handler(version(app.authorize(auth.ScopeArticlesAdmin, app.getDeletedArticlesFunction)))
+
+	router.
+		Methods("GET").
+		Path("/admin/articles/{id}").
+		Handler(version(app.authorize(auth.ScopeArticlesAdmin, app.getAdminArticleFunction)))
+
+	router.
+		Methods("POST").
+		Path("/admin/articles/{id}/restore").
+		Handler(version(app.authorize(auth.ScopeArticlesAdmin, app.restoreArticleFunction)))
+
+	router.
+		Methods("GET").
+		Path("/admin/export").
+		Handler(version(app.authorize(auth.ScopeArticlesAdmin, app.getExportFunction)))
+
+	router.
+		Methods("GET").
+		Path("/admin/tags").
+		Handler(version(app.authorize(auth.ScopeTagsAdmin, app.getTagCountsFunction)))
+
+	router.
+		Methods("POST").
+		Path("/admin/tags/{tagName}/merge").
+		Handler(version(app.authorize(auth.ScopeTagsAdmin, app.mergeTagsFunction)))
+
+	router.
+		Methods("GET").
+		Path("/admin/keys").
+		Handler(version(app.authorize(auth.ScopeKeysAdmin, app.getAPIKeysFunction)))
+
+	router.
+		Methods("POST").
+		Path("/admin/keys").
+		Handler(version(app.authorize(auth.ScopeKeysAdmin, app.postAPIKeyFunction)))
+
+	router.
+		Methods("DELETE").
+		Path("/admin/keys/{id}").
+		Handler(version(app.authorize(auth.ScopeKeysAdmin, app.deleteAPIKeyFunction)))
 }
 
 func (app *App) getArticleFunction(w http.ResponseWriter, r *http.Request) {
@@ -133,8 +352,14 @@ func (app *App) getArticleFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	article, tags, err := app.repo.GetArticleByID(id)
-	if err != nil {
+	article, tags, err := app.reader(r).GetArticleByID(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err != nil {
 		err = handleError(w, err.Error(), http.StatusInternalServerError)
 		if err != nil {
 			app.logger.Errorf("error sending error response because: %v", err)
@@ -147,23 +372,138 @@ func (app *App) getArticleFunction(w http.ResponseWriter, r *http.Request) {
 		tagsList = append(tagsList, tag.Name)
 	}
 
+	authors, err := app.repo.GetArticleAuthors([]int{article.Id})
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
 	response := Article{
-		Id:    fmt.Sprintf("%d", article.Id),
-		Title: article.Title,
-		Date:  article.Date.Format("01-02-2006"),
-		Body:  article.Body,
-		Tags:  tagsList,
+		Id:      fmt.Sprintf("%d", article.Id),
+		Title:   article.Title,
+		Date:    articleDate(r, article.Date),
+		Body:    article.Body,
+		Tags:    tagsList,
+		Authors: authorResponses(authors[article.Id]),
 	}
 
-	w.Header().Add(HeaderContentType, ContentTypeJSON)
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		panic(err)
+	app.writeArticleResponse(w, r, response, article)
+}
+
+func (app *App) getArticlesFunction(w http.ResponseWriter, r *http.Request) {
+
+	filter, err := listFilter(r)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking list request because: %v", err)
+		}
+		return
+	}
+
+	if author := r.URL.Query().Get("author"); author != "" {
+		authorID, err := strconv.Atoi(author)
+		if err != nil {
+			err = handleError(w, "provided author is not a number", http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("provided author is not a number")
+			}
+			return
+		}
+		filter.AuthorId = authorID
+	}
+
+	app.listArticles(w, r, filter)
+}
+
+// listArticles writes the filtered articles along with their tags and authors
+func (app *App) listArticles(w http.ResponseWriter, r *http.Request, filter model.ArticleFilter) {
+
+	articles, err := app.repo.ListArticles(filter)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	var ids []int
+	for _, article := range articles {
+		ids = append(ids, article.Id)
+	}
+
+	tags, err := app.repo.GetArticleTags(ids)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	authors, err := app.repo.GetArticleAuthors(ids)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := ArticlesResponse{
+		Count:    len(articles),
+		Articles: []Article{},
+	}
+	for _, article := range articles {
+		response.Articles = append(response.Articles, Article{
+			Id:      fmt.Sprintf("%d", article.Id),
+			Title:   article.Title,
+			Date:    articleDate(r, article.Date),
+			Body:    article.Body,
+			Tags:    tags[article.Id],
+			Authors: authorResponses(authors[article.Id]),
+		})
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+// listFilter reads the tag and paging parameters shared by article listings
+func listFilter(r *http.Request) (model.ArticleFilter, error) {
+	params := r.URL.Query()
+	filter := model.ArticleFilter{
+		Tag: params.Get("tag"),
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxListLimit {
+			return filter, fmt.Errorf("limit must be a number between 1 and %d", maxListLimit)
+		}
+		filter.Limit = value
+	}
+
+	if offset := params.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return filter, errors.New("offset must be a positive number")
+		}
+		filter.Offset = value
 	}
+
+	return filter, nil
 }
 
 func (app *App) postArticleFunction(w http.ResponseWriter, r *http.Request) {
 
+	if _, ok := app.acceptable(w, r, CreateArticleResponse{}); !ok {
+		return
+	}
+
 	var article Article
 	err := json.NewDecoder(r.Body).Decode(&article)
 	if err != nil {
@@ -174,25 +514,44 @@ func (app *App) postArticleFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = checkArticlePost(&article, w)
+	err = validateArticle(&article)
 	if err != nil {
-		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		err = handleError(w, err.Error(), http.StatusBadRequest)
 		if err != nil {
 			app.logger.Errorf("error checking post request because: %v", err)
 		}
 		return
 	}
 
-	date, _ := time.Parse("2006-01-02", article.Date)
-	id, _ := strconv.Atoi(article.Id)
-	articleModel := model.Article{
-		Id:    id,
-		Title: article.Title,
-		Date:  date,
-		Body:  article.Body,
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	articleModel, err := toArticleModel(&article, loc)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking post request because: %v", err)
+		}
+		return
 	}
 
-	articleRes, _, err := app.repo.CreateArticle(articleModel, article.Tags)
+	authorIDs, err := app.checkAuthorIDs(article.AuthorIds)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking post request because: %v", err)
+		}
+		return
+	}
+
+	authorIDs = ownAuthorIDs(r, authorIDs)
+	if !app.permit(w, r, policy.ActionCreate, policy.Resource{Kind: policy.KindArticle, OwnerIds: authorIDs}) {
+		return
+	}
+
+	articleRes, _, err := app.repo.CreateArticle(articleModel, article.Tags, requestAuthor(r))
 	if err != nil {
 		err = handleError(w, err.Error(), http.StatusInternalServerError)
 		if err != nil {
@@ -201,48 +560,269 @@ func (app *App) postArticleFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(authorIDs) > 0 {
+		err = app.repo.SetArticleAuthors(articleRes.Id, authorIDs)
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+	}
+
+	app.syncIndex(*articleRes, article.Tags)
+
 	response := CreateArticleResponse{
 		Success: true,
 		Id:      articleRes.Id,
 	}
 
-	w.Header().Add(HeaderContentType, ContentTypeJSON)
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		app.logger.Errorf("error sending error response because: %v", err)
+	app.writeResponse(w, r, http.StatusCreated, response)
+}
+
+func (app *App) putArticleFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, CreateArticleResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var article Article
+	err := json.NewDecoder(r.Body).Decode(&article)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error decoding json put body because: %v", err)
+		}
+		return
+	}
+
+	if article.Id != "" && article.Id != id {
+		err = handleError(w, "id in body does not match id in path", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("id in body does not match id in path")
+		}
+		return
+	}
+	article.Id = id
+
+	err = validateArticle(&article)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking put request because: %v", err)
+		}
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	articleModel, err := toArticleModel(&article, loc)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking put request because: %v", err)
+		}
+		return
+	}
+
+	authorIDs, err := app.checkAuthorIDs(article.AuthorIds)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking put request because: %v", err)
+		}
+		return
+	}
+
+	if !app.permitArticle(w, r, policy.ActionUpdate, articleModel.Id) {
+		return
+	}
+
+	// authors may not hand their articles over to somebody else
+	if authorIDs != nil && !app.permit(w, r, policy.ActionUpdate, policy.Resource{Kind: policy.KindArticle, OwnerIds: authorIDs}) {
+		return
+	}
+
+	current, _, err := app.repo.GetAdminArticleByID(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
 		return
 	}
+
+	if !app.checkIfMatch(w, r, current) {
+		return
+	}
+	articleModel.Version = current.Version
+
+	app.updateArticle(w, r, articleModel, article.Tags, authorIDs)
 }
 
-func checkArticlePost(article *Article, w http.ResponseWriter) error {
+// updateArticle stores a new version of the article, keeps the search index in
+// sync and writes the response.
+// A nil authorIDs leaves the authors of the article unchanged.
+func (app *App) updateArticle(w http.ResponseWriter, r *http.Request, article model.Article, tags []string, authorIDs []int) {
+
+	articleRes, _, err := app.repo.UpdateArticle(article, tags, requestAuthor(r))
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err == repo.ErrVersionConflict {
+		err = handleError(w, err.Error(), http.StatusPreconditionFailed)
+		if err != nil {
+			app.logger.Errorf("article %d has been changed since it was read", article.Id)
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	if authorIDs != nil {
+		err = app.repo.SetArticleAuthors(articleRes.Id, authorIDs)
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+	}
+
+	app.syncIndex(*articleRes, tags)
+
+	response := CreateArticleResponse{
+		Success: true,
+		Id:      articleRes.Id,
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+// validateArticle checks the fields of a request article, normalising its tags
+// on the way
+func validateArticle(article *Article) error {
+	article.Tags = normalizeTags(article.Tags)
+
 	if article.Id == "" {
-		err := handleError(w, "no id provided", http.StatusBadRequest)
-		return err
+		return errors.New("no id provided")
 	}
 
 	if article.Title == "" {
-		err := handleError(w, "no title provided", http.StatusBadRequest)
-		return err
+		return errors.New("no title provided")
 	}
 
 	if article.Date == "" {
-		err := handleError(w, "no date provided", http.StatusBadRequest)
-		return err
+		return errors.New("no date provided")
 	}
 
 	if article.Body == "" {
-		err := handleError(w, "no body provided", http.StatusBadRequest)
-		return err
+		return errors.New("no body provided")
 	}
 
 	if len(article.Tags) <= 0 {
-		err := handleError(w, "no tags provided", http.StatusBadRequest)
-		return err
+		return errors.New("no tags provided")
 	}
 
 	return nil
 }
 
+// normalizeTags trims the tags and drops the empty and repeated ones, keeping
+// the order they were given in
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// toArticleModel converts a validated request article into the stored model,
+// articles without a status are published straight away. Timestamps without an
+// offset are read in loc.
+func toArticleModel(article *Article, loc *time.Location) (model.Article, error) {
+	date, err := parseDate(article.Date, loc)
+	if err != nil {
+		return model.Article{}, err
+	}
+
+	id, _ := strconv.Atoi(article.Id)
+	result := model.Article{
+		Id:     id,
+		Title:  article.Title,
+		Date:   date,
+		Body:   article.Body,
+		Status: article.Status,
+	}
+
+	switch result.Status {
+	case "":
+		result.Status = model.StatusPublished
+	case model.StatusDraft, model.StatusScheduled, model.StatusPublished, model.StatusArchived:
+	default:
+		return model.Article{}, fmt.Errorf("unknown status %s provided", article.Status)
+	}
+
+	if article.PublishAt != "" {
+		publishAt, err := parseTimestamp(article.PublishAt, loc)
+		if err != nil {
+			return model.Article{}, errors.New("bad publish_at format provided")
+		}
+		publishAt = publishAt.UTC()
+		result.PublishAt = &publishAt
+	}
+
+	if result.Status == model.StatusScheduled && result.PublishAt == nil {
+		return model.Article{}, errors.New("no publish_at provided for scheduled article")
+	}
+
+	return result, nil
+}
+
+// syncIndex makes the article searchable once it is published and removes it
+// from the index for any other status.
+func (app *App) syncIndex(article model.Article, tags []string) {
+	if article.Status != model.StatusPublished {
+		if err := app.index.Remove(article.Id); err != nil {
+			app.logger.Errorf("error removing article %d from index because: %v", article.Id, err)
+		}
+		return
+	}
+
+	if err := app.index.Index(article, tags); err != nil {
+		app.logger.Errorf("error indexing article %d because: %v", article.Id, err)
+	}
+}
+
 func (app *App) getTagsFunction(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tagName, ok := vars["tagName"]
@@ -271,16 +851,23 @@ func (app *App) getTagsFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dateStr, err := time.Parse("20060102", date)
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	day, err := parseDate(date, loc)
 	if err != nil {
-		err := handleError(w, "bad date format provided", http.StatusBadRequest)
+		err := handleError(w, err.Error(), http.StatusBadRequest)
 		if err != nil {
 			app.logger.Errorf("bad date format provided")
 		}
 		return
 	}
 
-	tagCount, err := app.repo.CountTagForDateName(tagName, dateStr.String())
+	reader := app.reader(r)
+
+	tagCount, err := reader.CountTagForDateName(tagName, day)
 	if err != nil {
 		err = handleError(w, err.Error(), http.StatusInternalServerError)
 		if err != nil {
@@ -289,7 +876,7 @@ func (app *App) getTagsFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	relatedTags, err := app.repo.GetRelatedTagForDateAndName(tagName, dateStr.String())
+	relatedTags, err := reader.GetRelatedTagForDateAndName(tagName, day)
 	if err != nil {
 		err = handleError(w, err.Error(), http.StatusInternalServerError)
 		if err != nil {
@@ -298,7 +885,7 @@ func (app *App) getTagsFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	taggedArticles, err := app.repo.GetArticleIDForDateAndTag(tagName, dateStr.String())
+	taggedArticles, err := reader.GetArticleIDForDateAndTag(tagName, day)
 	if err != nil {
 		err = handleError(w, err.Error(), http.StatusInternalServerError)
 		if err != nil {
@@ -307,19 +894,17 @@ func (app *App) getTagsFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := TagSummaryResponse{
+	var response interface{} = TagSummaryResponse{
 		Tag:         tagName,
 		Count:       tagCount,
 		Articles:    taggedArticles,
 		RelatedTags: relatedTags,
 	}
-
-	w.Header().Add(HeaderContentType, ContentTypeJSON)
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		app.logger.Errorf("error sending error response because: %v", err)
-		return
+	if requestVersion(r) == Version1 {
+		response = TagSummaryResponseV1(response.(TagSummaryResponse))
 	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
 }
 
 func (app *App) pingFunction(w http.ResponseWriter, r *http.Request) {
@@ -327,6 +912,38 @@ func (app *App) pingFunction(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// requestAuthor returns the name recorded against any change made by the
+// request, the authenticated principal wins over the author header.
+func requestAuthor(r *http.Request) string {
+	if principal := auth.PrincipalFrom(r.Context()); principal != nil && principal.Subject != "" {
+		return principal.Subject
+	}
+	return r.Header.Get(HeaderAuthor)
+}
+
+// idFromPath validates the numeric resource id in the request path, any
+// problem is written to w and reported as false.
+func (app *App) idFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		err := handleError(w, "no id provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no id provided")
+		}
+		return "", false
+	}
+
+	if _, err := strconv.Atoi(id); err != nil {
+		err = handleError(w, "provided id is not a number", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("provided id is not a number")
+		}
+		return "", false
+	}
+
+	return id, true
+}
+
 func handleError(w http.ResponseWriter, message string, statusCode int) error {
 
 	msg := ResponseError(message)
@@ -336,14 +953,14 @@ func handleError(w http.ResponseWriter, message string, statusCode int) error {
 	})
 
 	if err != nil {
-		log.NewLogger().Errorf("error in marshaling JSON success response because: %v", err)
+		logger.Errorf("error in marshaling JSON success response because: %v", err)
 		return err
 	}
 
 	w.Header().Add(HeaderContentType, ContentTypeJSON)
 	w.WriteHeader(statusCode)
 	if _, err := w.Write(body); err != nil {
-		log.NewLogger().Errorf("error writing response because: %v", err)
+		logger.Errorf("error writing response because: %v", err)
 		return err
 	}
 
@@ -8,8 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"rest-article/auth"
 	"rest-article/log"
 	"rest-article/repo"
+	"rest-article/search"
 	"testing"
 )
 
@@ -20,6 +22,64 @@ func NewMockArticleRepo(err error) repo.Repo {
 	return mockRepo
 }
 
+// testAppOption changes the app built by newTestApp before its routes are set
+// up
+type testAppOption func(app *App)
+
+// withIndex searches index instead of an empty in memory index
+func withIndex(index search.Index) testAppOption {
+	return func(app *App) {
+		app.index = index
+	}
+}
+
+// withStaticKeys authenticates requests with keys, and the keys of the mock
+// repo
+func withStaticKeys(keys ...auth.StaticKey) testAppOption {
+	return func(app *App) {
+		app.authenticator = auth.NewAuthenticator(keys, app.repo.(auth.KeyStore), nil)
+	}
+}
+
+// withRateLimits applies limits to the requests of the app
+func withRateLimits(limits *RateLimits) testAppOption {
+	return func(app *App) {
+		app.SetRateLimits(limits)
+	}
+}
+
+// withCORS answers cross origin requests of the app following cors
+func withCORS(cors *CORS) testAppOption {
+	return func(app *App) {
+		app.SetCORS(cors)
+	}
+}
+
+// withCompression compresses the responses of the app following compression
+func withCompression(compression *Compression) testAppOption {
+	return func(app *App) {
+		app.SetCompression(compression)
+	}
+}
+
+// newTestApp returns an app on the mock repo and an in memory index with its
+// routes set up, name tells its log lines apart
+func newTestApp(name string, options ...testAppOption) *App {
+	app := &App{
+		Database: nil,
+		ctx:      nil,
+		repo:     NewMockArticleRepo(nil),
+		index:    search.NewMemoryIndex(),
+		Router:   mux.NewRouter(),
+		logger:   log.NewLogger().WithField("test", name),
+	}
+	for _, option := range options {
+		option(app)
+	}
+	app.SetupRouter()
+	return app
+}
+
 func TestGetArticleFunction(t *testing.T) {
 	app := &App{
 		Database: nil,
@@ -41,6 +101,21 @@ func TestGetArticleFunction(t *testing.T) {
 
 }
 
+func TestGetArticleFunctionDeleted(t *testing.T) {
+	app := newTestApp("TestGetArticleFunctionDeleted")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/"+repo.MockDeletedArticleID, nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, ResponseError("article not found"), respBody.Error)
+}
+
 func TestGetArticleFunctionBadId(t *testing.T) {
 	app := &App{
 		Database: nil,
@@ -221,3 +296,54 @@ func TestGetTagsFunctionBadTag(t *testing.T) {
 
 	assert.Equal(t, false, respBody.Success)
 }
+
+func TestPostArticleFunctionScheduledWithoutPublishAt(t *testing.T) {
+	app := newTestApp("TestPostArticleFunctionScheduledWithoutPublishAt")
+
+	reqBody := PostArticleRequest{Article{
+		Id:     "1",
+		Title:  "test article",
+		Date:   "2020-02-01",
+		Body:   "test art",
+		Tags:   []string{"science", "math"},
+		Status: "scheduled",
+	}}
+
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/articles", bytes.NewReader(body))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, respBody.Error, ResponseError("no publish_at provided for scheduled article"))
+	assert.Equal(t, resp.Code, http.StatusBadRequest)
+}
+
+func TestPostArticleFunctionDraftNotIndexed(t *testing.T) {
+	app := newTestApp("TestPostArticleFunctionDraftNotIndexed")
+
+	reqBody := PostArticleRequest{Article{
+		Id:     "1",
+		Title:  "test article",
+		Date:   "2020-02-01",
+		Body:   "test art",
+		Tags:   []string{"science", "math"},
+		Status: "draft",
+	}}
+
+	body, _ := json.Marshal(reqBody)
+
+	req := httptest.NewRequest(http.MethodPost, "/articles", bytes.NewReader(body))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	results, _ := app.index.Search(search.Query{Text: "test"})
+
+	assert.Equal(t, resp.Code, http.StatusCreated)
+	assert.Len(t, results, 0)
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"rest-article/database/model"
+	"rest-article/policy"
+	"strconv"
+)
+
+type Author struct {
+	XMLName xml.Name `json:"-" xml:"author" yaml:"-"`
+	Id      string   `json:"id" xml:"id" yaml:"id"`
+	Name    string   `json:"name" xml:"name" yaml:"name"`
+	Email   string   `json:"email,omitempty" xml:"email,omitempty" yaml:"email,omitempty"`
+	Bio     string   `json:"bio,omitempty" xml:"bio,omitempty" yaml:"bio,omitempty"`
+}
+
+type AuthorsResponse struct {
+	XMLName xml.Name `json:"-" xml:"authors" yaml:"-"`
+	Count   int      `json:"count" xml:"count" yaml:"count"`
+	Authors []Author `json:"authors" xml:"author" yaml:"authors"`
+}
+
+type CreateAuthorResponse struct {
+	XMLName xml.Name `json:"-" xml:"created" yaml:"-"`
+	Success bool     `json:"success" xml:"success" yaml:"success"`
+	Id      int      `json:"id" xml:"id" yaml:"id"`
+}
+
+func (app *App) getAuthorsFunction(w http.ResponseWriter, r *http.Request) {
+
+	authors, err := app.repo.GetAuthors()
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := AuthorsResponse{
+		Count:   len(authors),
+		Authors: authorResponses(authors),
+	}
+	if response.Authors == nil {
+		response.Authors = []Author{}
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) getAuthorFunction(w http.ResponseWriter, r *http.Request) {
+
+	author, ok := app.authorFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeResponse(w, r, http.StatusOK, authorResponse(author))
+}
+
+func (app *App) postAuthorFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, CreateAuthorResponse{}); !ok {
+		return
+	}
+
+	var author Author
+	err := json.NewDecoder(r.Body).Decode(&author)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error decoding json post body because: %v", err)
+		}
+		return
+	}
+
+	if author.Name == "" {
+		err = handleError(w, "no name provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no name provided")
+		}
+		return
+	}
+
+	if !app.permit(w, r, policy.ActionCreate, policy.Resource{Kind: policy.KindAuthor}) {
+		return
+	}
+
+	authorRes, err := app.repo.CreateAuthor(model.Author{
+		Name:  author.Name,
+		Email: author.Email,
+		Bio:   author.Bio,
+	})
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := CreateAuthorResponse{
+		Success: true,
+		Id:      authorRes.Id,
+	}
+
+	app.writeResponse(w, r, http.StatusCreated, response)
+}
+
+func (app *App) putAuthorFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, Author{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var author Author
+	err := json.NewDecoder(r.Body).Decode(&author)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error decoding json put body because: %v", err)
+		}
+		return
+	}
+
+	if author.Name == "" {
+		err = handleError(w, "no name provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no name provided")
+		}
+		return
+	}
+
+	authorID, _ := strconv.Atoi(id)
+	if !app.permit(w, r, policy.ActionUpdate, policy.Resource{Kind: policy.KindAuthor, OwnerIds: []int{authorID}}) {
+		return
+	}
+
+	authorRes, err := app.repo.UpdateAuthor(model.Author{
+		Id:    authorID,
+		Name:  author.Name,
+		Email: author.Email,
+		Bio:   author.Bio,
+	})
+	if err == sql.ErrNoRows {
+		err = handleError(w, "author not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("author not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	app.writeResponse(w, r, http.StatusOK, authorResponse(authorRes))
+}
+
+func (app *App) deleteAuthorFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, ActionResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	authorID, _ := strconv.Atoi(id)
+	if !app.permit(w, r, policy.ActionDelete, policy.Resource{Kind: policy.KindAuthor, OwnerIds: []int{authorID}}) {
+		return
+	}
+
+	err := app.repo.DeleteAuthor(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "author not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("author not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	app.writeActionResponse(w, r, id)
+}
+
+func (app *App) getAuthorArticlesFunction(w http.ResponseWriter, r *http.Request) {
+
+	author, ok := app.authorFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := listFilter(r)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking list request because: %v", err)
+		}
+		return
+	}
+	filter.AuthorId = author.Id
+
+	app.listArticles(w, r, filter)
+}
+
+// authorFromPath loads the author with the id in the request path, any problem
+// is written to w and reported as false.
+func (app *App) authorFromPath(w http.ResponseWriter, r *http.Request) (*model.Author, bool) {
+
+	id := mux.Vars(r)["id"]
+	if _, err := strconv.Atoi(id); err != nil {
+		err = handleError(w, "provided id is not a number", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("provided id is not a number")
+		}
+		return nil, false
+	}
+
+	author, err := app.repo.GetAuthorByID(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "author not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("author not found")
+		}
+		return nil, false
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return nil, false
+	}
+
+	return author, true
+}
+
+// checkAuthorIDs converts the author ids of an article request and makes sure
+// every author exists, a nil list stays nil.
+func (app *App) checkAuthorIDs(ids []string) ([]int, error) {
+	if ids == nil {
+		return nil, nil
+	}
+
+	authorIDs := []int{}
+	for _, id := range ids {
+		authorID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.New("provided author id is not a number")
+		}
+
+		_, err = app.repo.GetAuthorByID(id)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("author %s not found", id)
+		} else if err != nil {
+			return nil, err
+		}
+
+		authorIDs = append(authorIDs, authorID)
+	}
+
+	return authorIDs, nil
+}
+
+func authorResponse(author *model.Author) Author {
+	return Author{
+		Id:    fmt.Sprintf("%d", author.Id),
+		Name:  author.Name,
+		Email: author.Email,
+		Bio:   author.Bio,
+	}
+}
+
+func authorResponses(authors []*model.Author) []Author {
+	var result []Author
+	for _, author := range authors {
+		result = append(result, authorResponse(author))
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetAuthorsFunction(t *testing.T) {
+	app := newTestApp("TestGetAuthorsFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/authors", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody AuthorsResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, respBody.Count)
+	assert.Equal(t, "test author 1", respBody.Authors[0].Name)
+}
+
+func TestPostAuthorFunctionNoName(t *testing.T) {
+	app := newTestApp("TestPostAuthorFunctionNoName")
+
+	body, _ := json.Marshal(Author{Email: "author@test.com"})
+
+	req := httptest.NewRequest(http.MethodPost, "/authors", bytes.NewReader(body))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, ResponseError("no name provided"), respBody.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetAuthorArticlesFunction(t *testing.T) {
+	app := newTestApp("TestGetAuthorArticlesFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/authors/1/articles?limit=5", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ArticlesResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 3, respBody.Count)
+	assert.Equal(t, "test author 1", respBody.Articles[0].Authors[0].Name)
+	assert.Equal(t, []string{"test", "test2"}, respBody.Articles[0].Tags)
+}
+
+func TestGetArticleFunctionIncludesAuthors(t *testing.T) {
+	app := newTestApp("TestGetArticleFunctionIncludesAuthors")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody Article
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, respBody.Authors, 1)
+	assert.Equal(t, "1", respBody.Authors[0].Id)
+}
+
+func TestGetArticlesFunctionBadAuthor(t *testing.T) {
+	app := newTestApp("TestGetArticlesFunctionBadAuthor")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles?author=abc", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, ResponseError("provided author is not a number"), respBody.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,341 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"rest-article/database/model"
+	"time"
+)
+
+// DumpVersion is the version of the dump format written by ExportDump
+const DumpVersion = 1
+
+// Dump record kind constants, a dump holds a header followed by the authors
+// and then the articles
+const (
+	DumpKindHeader  = "header"
+	DumpKindAuthor  = "author"
+	DumpKindArticle = "article"
+)
+
+// ContentTypeGzip is the media type of a gzipped download
+const ContentTypeGzip = "application/gzip"
+
+// HeaderContentDisposition names the file a download is saved as
+const HeaderContentDisposition = "Content-Disposition"
+
+// dumpPageSize is the number of articles read from the repo at once
+const dumpPageSize = 100
+
+// DumpRecord is a single line of a dump, Kind tells which of the other fields
+// is set
+type DumpRecord struct {
+	Kind      string       `json:"kind"`
+	Version   int          `json:"version,omitempty"`
+	CreatedAt *time.Time   `json:"created_at,omitempty"`
+	Author    *DumpAuthor  `json:"author,omitempty"`
+	Article   *DumpArticle `json:"article,omitempty"`
+}
+
+// DumpAuthor is an author as dumped
+type DumpAuthor struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Bio   string `json:"bio,omitempty"`
+}
+
+// DumpArticle is an article as dumped, with everything needed to restore it as
+// it was
+type DumpArticle struct {
+	Id        int            `json:"id"`
+	Title     string         `json:"title"`
+	Date      string         `json:"date"`
+	Body      string         `json:"body"`
+	Status    string         `json:"status"`
+	PublishAt *time.Time     `json:"publish_at,omitempty"`
+	DeletedAt *time.Time     `json:"deleted_at,omitempty"`
+	Version   int            `json:"version"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Tags      []string       `json:"tags"`
+	AuthorIds []int          `json:"author_ids,omitempty"`
+	Revisions []DumpRevision `json:"revisions,omitempty"`
+}
+
+// DumpRevision is a revision of an article as dumped
+type DumpRevision struct {
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Date      string    `json:"date"`
+	Body      string    `json:"body"`
+	Tags      []string  `json:"tags"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestoreResponse reports what a restore loaded
+type RestoreResponse struct {
+	Authors  int `json:"authors"`
+	Articles int `json:"articles"`
+}
+
+// ExportDump writes every author and every article, drafts and soft deleted
+// ones included, with their tags, authors and revisions as JSON lines. The
+// first line is a header naming the dump version.
+func (app *App) ExportDump(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+
+	now := time.Now().UTC()
+	if err := encoder.Encode(DumpRecord{Kind: DumpKindHeader, Version: DumpVersion, CreatedAt: &now}); err != nil {
+		return err
+	}
+
+	authors, err := app.repo.GetAuthors()
+	if err != nil {
+		return err
+	}
+	for _, author := range authors {
+		record := DumpRecord{Kind: DumpKindAuthor, Author: &DumpAuthor{
+			Id:    author.Id,
+			Name:  author.Name,
+			Email: author.Email,
+			Bio:   author.Bio,
+		}}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	afterID := 0
+	for {
+		dumps, err := app.repo.DumpArticles(afterID, dumpPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, dump := range dumps {
+			if err := encoder.Encode(DumpRecord{Kind: DumpKindArticle, Article: dumpArticle(dump)}); err != nil {
+				return err
+			}
+			afterID = dump.Article.Id
+		}
+
+		if len(dumps) < dumpPageSize {
+			return nil
+		}
+	}
+}
+
+func dumpArticle(dump *model.ArticleDump) *DumpArticle {
+	article := dump.Article
+	result := &DumpArticle{
+		Id:        article.Id,
+		Title:     article.Title,
+		Date:      formatDate(article.Date),
+		Body:      article.Body,
+		Status:    article.Status,
+		PublishAt: article.PublishAt,
+		DeletedAt: article.DeletedAt,
+		Version:   article.Version,
+		UpdatedAt: article.UpdatedAt.UTC(),
+		Tags:      dump.Tags,
+		AuthorIds: dump.AuthorIds,
+	}
+	if result.Tags == nil {
+		result.Tags = []string{}
+	}
+
+	for _, revision := range dump.Revisions {
+		result.Revisions = append(result.Revisions, DumpRevision{
+			Revision:  revision.Revision,
+			Title:     revision.Title,
+			Date:      formatDate(revision.Date),
+			Body:      revision.Body,
+			Tags:      revision.Tags,
+			Author:    revision.Author,
+			CreatedAt: revision.CreatedAt.UTC(),
+		})
+	}
+
+	return result
+}
+
+// RestoreDump loads a dump written by ExportDump into the repo, which should
+// hold no articles or authors yet. A gzipped dump is recognised by its magic
+// number. The restore stops at the first record that can not be loaded,
+// articles loaded before it are kept.
+func (app *App) RestoreDump(r io.Reader) (*RestoreResponse, error) {
+
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = bufio.NewReader(gzipReader)
+	}
+
+	response := &RestoreResponse{}
+	first := true
+	for number := 1; ; number++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return response, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		if loadErr := app.loadDumpRecord(line, first, response); loadErr != nil {
+			return response, fmt.Errorf("line %d: %v", number, loadErr)
+		}
+		first = false
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return response, nil
+}
+
+// loadDumpRecord loads a single line of a dump, the first must be the header
+func (app *App) loadDumpRecord(line []byte, first bool, response *RestoreResponse) error {
+
+	var record DumpRecord
+	if err := json.Unmarshal(line, &record); err != nil {
+		return err
+	}
+
+	if first && record.Kind != DumpKindHeader {
+		return errors.New("no dump header provided")
+	}
+
+	switch record.Kind {
+	case DumpKindHeader:
+		if !first {
+			return errors.New("dump header provided twice")
+		}
+		if record.Version != DumpVersion {
+			return fmt.Errorf("unsupported dump version %d, expected %d", record.Version, DumpVersion)
+		}
+
+	case DumpKindAuthor:
+		if record.Author == nil {
+			return errors.New("no author provided")
+		}
+		author := model.Author{
+			Id:    record.Author.Id,
+			Name:  record.Author.Name,
+			Email: record.Author.Email,
+			Bio:   record.Author.Bio,
+		}
+		if err := app.repo.LoadAuthor(author); err != nil {
+			return err
+		}
+		response.Authors++
+
+	case DumpKindArticle:
+		if record.Article == nil {
+			return errors.New("no article provided")
+		}
+		dump, err := loadArticle(record.Article)
+		if err != nil {
+			return err
+		}
+		if err := app.repo.LoadArticle(*dump); err != nil {
+			return err
+		}
+		if dump.Article.DeletedAt == nil {
+			app.syncIndex(dump.Article, dump.Tags)
+		}
+		response.Articles++
+
+	default:
+		return fmt.Errorf("unknown dump record kind %s", record.Kind)
+	}
+
+	return nil
+}
+
+func loadArticle(article *DumpArticle) (*model.ArticleDump, error) {
+	date, err := time.Parse(DateFormat, article.Date)
+	if err != nil {
+		return nil, errBadDate
+	}
+
+	dump := &model.ArticleDump{
+		Article: model.Article{
+			Id:        article.Id,
+			Title:     article.Title,
+			Date:      date,
+			Body:      article.Body,
+			Status:    article.Status,
+			PublishAt: article.PublishAt,
+			DeletedAt: article.DeletedAt,
+			Version:   article.Version,
+			UpdatedAt: article.UpdatedAt,
+		},
+		Tags:      article.Tags,
+		AuthorIds: article.AuthorIds,
+	}
+
+	for _, revision := range article.Revisions {
+		revisionDate, err := time.Parse(DateFormat, revision.Date)
+		if err != nil {
+			return nil, fmt.Errorf("revision %d: %v", revision.Revision, errBadDate)
+		}
+		dump.Revisions = append(dump.Revisions, &model.Revision{
+			ArticleId: article.Id,
+			Revision:  revision.Revision,
+			Title:     revision.Title,
+			Date:      revisionDate,
+			Body:      revision.Body,
+			Tags:      revision.Tags,
+			Author:    revision.Author,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	return dump, nil
+}
+
+// getExportFunction streams a dump of every author and article, gzipped when
+// the gzip parameter is true. As the dump is streamed, a failure halfway
+// through cuts the download short rather than changing the status.
+func (app *App) getExportFunction(w http.ResponseWriter, r *http.Request) {
+
+	gzipped := r.URL.Query().Get("gzip") == "true"
+	filename := fmt.Sprintf("rest-article-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+
+	w.Header().Set(HeaderContentType, ContentTypeJSONL)
+	var out io.Writer = w
+	if gzipped {
+		filename += ".gz"
+		w.Header().Set(HeaderContentType, ContentTypeGzip)
+		gzipWriter := gzip.NewWriter(w)
+		defer func() {
+			if err := gzipWriter.Close(); err != nil {
+				app.logger.Errorf("error finishing export because: %v", err)
+			}
+		}()
+		out = gzipWriter
+	}
+	w.Header().Set(HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := app.ExportDump(out); err != nil {
+		app.logger.Errorf("error exporting articles because: %v", err)
+		return
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/search"
+	"strings"
+	"testing"
+)
+
+func readDump(t *testing.T, r io.Reader) []DumpRecord {
+	var records []DumpRecord
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var record DumpRecord
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.Nil(t, scanner.Err())
+	return records
+}
+
+func TestExportFunction(t *testing.T) {
+	app := newTestApp("TestExportFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/export", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeJSONL, resp.Header().Get(HeaderContentType))
+	assert.Contains(t, resp.Header().Get(HeaderContentDisposition), `.jsonl"`)
+
+	records := readDump(t, resp.Body)
+	assert.Len(t, records, 6)
+	assert.Equal(t, DumpKindHeader, records[0].Kind)
+	assert.Equal(t, DumpVersion, records[0].Version)
+	assert.Equal(t, DumpKindAuthor, records[1].Kind)
+	assert.Equal(t, "test author 1", records[1].Author.Name)
+
+	article := records[3].Article
+	assert.Equal(t, DumpKindArticle, records[3].Kind)
+	assert.Equal(t, 1, article.Id)
+	assert.Equal(t, "2020-03-01", article.Date)
+	assert.Equal(t, []string{"test", "test2"}, article.Tags)
+	assert.Equal(t, []int{1}, article.AuthorIds)
+	assert.Len(t, article.Revisions, 2)
+
+	deleted := records[5].Article
+	assert.Equal(t, 3, deleted.Id)
+	assert.NotNil(t, deleted.DeletedAt)
+}
+
+func TestExportFunctionGzip(t *testing.T) {
+	app := newTestApp("TestExportFunctionGzip")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/export?gzip=true", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeGzip, resp.Header().Get(HeaderContentType))
+	assert.Contains(t, resp.Header().Get(HeaderContentDisposition), `.jsonl.gz"`)
+
+	reader, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	assert.Len(t, readDump(t, reader), 6)
+}
+
+func TestRestoreDump(t *testing.T) {
+	source := newTestApp("TestRestoreDump")
+
+	var dump bytes.Buffer
+	assert.Nil(t, source.ExportDump(&dump))
+
+	// the mock repo holds articles 1 to 3 already, so restore them as 11 to 13
+	var moved bytes.Buffer
+	gzipWriter := gzip.NewWriter(&moved)
+	encoder := json.NewEncoder(gzipWriter)
+	for _, record := range readDump(t, &dump) {
+		if record.Article != nil {
+			record.Article.Id += 10
+		}
+		assert.Nil(t, encoder.Encode(record))
+	}
+	assert.Nil(t, gzipWriter.Close())
+
+	target := newTestApp("TestRestoreDump")
+	response, err := target.RestoreDump(&moved)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &RestoreResponse{Authors: 2, Articles: 3}, response)
+
+	results, _ := target.index.Search(search.Query{Text: "test"})
+	var ids []int
+	for _, result := range results {
+		ids = append(ids, result.Id)
+	}
+	assert.ElementsMatch(t, []int{11, 12}, ids)
+}
+
+func TestRestoreDumpErrors(t *testing.T) {
+	header := `{"kind": "header", "version": 1}` + "\n"
+	article := `{"kind": "article", "article": {"id": 2, "title": "t", "date": "2020-03-01", "body": "b", "status": "published", "version": 1, "tags": ["test"]}}` + "\n"
+
+	tests := []struct {
+		name     string
+		dump     string
+		err      string
+		articles int
+	}{
+		{"no header", article, "line 1: no dump header provided", 0},
+		{"unsupported version", `{"kind": "header", "version": 2}`, "line 1: unsupported dump version 2, expected 1", 0},
+		{"unknown kind", header + `{"kind": "tag"}`, "line 2: unknown dump record kind tag", 0},
+		{"bad date", header + strings.Replace(article, "2020-03-01", "March", 1), "line 2: bad date format provided", 0},
+		{"existing article", header + strings.Replace(article, `"id": 2`, `"id": 20`, 1) + "\n" + article,
+			"line 4: Error 1062: Duplicate entry '2' for key 'PRIMARY'", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestRestoreDumpErrors")
+
+			response, err := app.RestoreDump(strings.NewReader(test.dump))
+
+			assert.NotNil(t, err)
+			assert.Equal(t, test.err, err.Error())
+			assert.Equal(t, test.articles, response.Articles)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compression header constants
+const (
+	HeaderAcceptEncoding  = "Accept-Encoding"
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderContentLength   = "Content-Length"
+)
+
+// Content coding constants, deflate is the zlib format of RFC 1950
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// encodingPreference orders the content codings the service offers, the
+// first is picked when the client rates several the same.
+var encodingPreference = []string{EncodingGzip, EncodingDeflate}
+
+// compressors start a compressed stream of each content coding at the level
+var compressors = map[string]func(w io.Writer, level int) (io.WriteCloser, error){
+	EncodingGzip: func(w io.Writer, level int) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, level)
+	},
+	EncodingDeflate: func(w io.Writer, level int) (io.WriteCloser, error) {
+		return zlib.NewWriterLevel(w, level)
+	},
+}
+
+// Compression compresses responses of the listed media types once their body
+// reaches MinSize bytes. Level runs from 1, the fastest, to 9, the smallest,
+// 0 picks the default level.
+type Compression struct {
+	MinSize      int
+	ContentTypes []string
+	Level        int
+}
+
+// SetCompression compresses responses for clients that accept it, nil turns
+// compression off.
+func (app *App) SetCompression(compression *Compression) {
+	app.runtime.update(func(runtime *Runtime) { runtime.Compression = compression })
+}
+
+// compress negotiates the content coding from the Accept-Encoding header and
+// compresses the response when it is large enough and of an allowed type.
+// Entity tags are given a suffix per content coding, as each coding is a
+// representation of its own, and the suffix is taken off the conditional
+// headers of the request again before the handler compares them.
+func (app *App) compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compression := app.runtime.load().Compression
+		if compression == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: w,
+			compression:    compression,
+			encoding:       negotiateEncoding(r.Header.Get(HeaderAcceptEncoding)),
+			ifNoneMatch:    r.Header.Get(HeaderIfNoneMatch),
+			head:           r.Method == http.MethodHead,
+		}
+		defer func() {
+			if err := writer.Close(); err != nil {
+				app.logger.Errorf("error compressing response because: %v", err)
+			}
+		}()
+
+		if r.Header.Get(HeaderIfNoneMatch) != "" || r.Header.Get(HeaderIfMatch) != "" {
+			r = r.Clone(r.Context())
+			for _, header := range []string{HeaderIfNoneMatch, HeaderIfMatch} {
+				if value := r.Header.Get(header); value != "" {
+					r.Header.Set(header, decodedETags(value))
+				}
+			}
+		}
+
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// compressWriter holds back the response until it knows whether the body
+// reaches the minimum size, and from then on compresses what is written.
+type compressWriter struct {
+	http.ResponseWriter
+	compression *Compression
+	encoding    string
+	ifNoneMatch string
+	head        bool
+
+	status  int
+	buf     []byte
+	started bool
+	encoder io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	if cw.started {
+		if cw.encoder != nil {
+			return cw.encoder.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.compression.MinSize {
+		if err := cw.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close sends whatever is still held back and ends the compressed stream
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		if err := cw.start(); err != nil {
+			return err
+		}
+	}
+
+	if cw.encoder != nil {
+		return cw.encoder.Close()
+	}
+	return nil
+}
+
+// start sends the headers and the held back body, compressed when the body is
+// large enough and of an allowed type.
+func (cw *compressWriter) start() error {
+	cw.started = true
+	header := cw.Header()
+
+	compressible := len(cw.buf) > 0 && len(cw.buf) >= cw.compression.MinSize && cw.compressible()
+	if compressible {
+		addVary(cw, HeaderAcceptEncoding)
+	}
+
+	if compressible && cw.encoding != "" {
+		encoder, err := compressors[cw.encoding](cw.ResponseWriter, cw.level())
+		if err != nil {
+			return err
+		}
+		cw.encoder = encoder
+
+		header.Set(HeaderContentEncoding, cw.encoding)
+		header.Del(HeaderContentLength)
+		if etag := header.Get(HeaderETag); etag != "" {
+			header.Set(HeaderETag, encodedETag(etag, cw.encoding))
+		}
+	}
+
+	// a 304 answers for the coding the client holds, which it names in
+	// If-None-Match
+	if cw.status == http.StatusNotModified && cw.encoding != "" {
+		if etag := header.Get(HeaderETag); etag != "" && etagMatches(cw.ifNoneMatch, encodedETag(etag, cw.encoding), true) {
+			header.Set(HeaderETag, encodedETag(etag, cw.encoding))
+			addVary(cw, HeaderAcceptEncoding)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.encoder != nil {
+		_, err := cw.encoder.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible reports whether the response may be compressed, leaving alone
+// bodiless and partial responses, responses already carrying a coding and
+// media types outside the allowlist.
+func (cw *compressWriter) compressible() bool {
+	if cw.head || cw.status < http.StatusOK || cw.status == http.StatusNoContent ||
+		cw.status == http.StatusPartialContent || cw.status == http.StatusNotModified {
+		return false
+	}
+
+	header := cw.Header()
+	if header.Get(HeaderContentEncoding) != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get(HeaderContentType))
+	if err != nil {
+		return false
+	}
+	return containsFold(cw.compression.ContentTypes, mediaType)
+}
+
+func (cw *compressWriter) level() int {
+	if cw.compression.Level == 0 {
+		return gzip.DefaultCompression
+	}
+	return cw.compression.Level
+}
+
+// negotiateEncoding picks the content coding the Accept-Encoding header
+// prefers, an empty string leaves the response uncompressed.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, value := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(strings.TrimSpace(value), ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+		qualities[coding] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range encodingPreference {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+	return best
+}
+
+// encodedETag gives the entity tag the suffix of the content coding, as in
+// "abc" to "abc-gzip", keeping a weak tag weak.
+func encodedETag(etag, encoding string) string {
+	if !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// decodedETags takes the content coding suffixes off the entity tags of an
+// If-Match or If-None-Match header value.
+func decodedETags(header string) string {
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for encoding := range compressors {
+			tag = strings.Replace(tag, "-"+encoding+`"`, `"`, 1)
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", ")
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                         "",
+		"gzip":                     EncodingGzip,
+		"deflate":                  EncodingDeflate,
+		"gzip, deflate, br":        EncodingGzip,
+		"gzip;q=0.5, deflate":      EncodingDeflate,
+		"*":                        EncodingGzip,
+		"*, gzip;q=0":              EncodingDeflate,
+		"br":                       "",
+		"identity, gzip;q=0":       "",
+		"GZIP; q=1.0, deflate;q=1": EncodingGzip,
+	}
+
+	for acceptEncoding, expected := range tests {
+		assert.Equal(t, expected, negotiateEncoding(acceptEncoding), acceptEncoding)
+	}
+}
+
+func TestCompressGzip(t *testing.T) {
+	app := newTestApp("TestCompressGzip", withCompression(&Compression{ContentTypes: []string{ContentTypeJSON}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+	plain := resp.Body.Bytes()
+
+	req = httptest.NewRequest(http.MethodGet, "/v2/articles", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip, deflate")
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, EncodingGzip, resp.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, []string{HeaderAccept, HeaderAcceptEncoding}, resp.Header()[HeaderVary])
+
+	reader, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, plain, body)
+}
+
+func TestCompressDeflate(t *testing.T) {
+	app := newTestApp("TestCompressDeflate", withCompression(&Compression{ContentTypes: []string{ContentTypeAtom}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/articles.atom", nil)
+	req.Header.Set(HeaderAcceptEncoding, "deflate")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, EncodingDeflate, resp.Header().Get(HeaderContentEncoding))
+
+	reader, err := zlib.NewReader(resp.Body)
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), "<feed")
+}
+
+func TestCompressSkipped(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression *Compression
+	}{
+		{"below minimum size", &Compression{MinSize: 1 << 20, ContentTypes: []string{ContentTypeJSON}}},
+		{"content type not allowed", &Compression{ContentTypes: []string{ContentTypeCSV}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestCompressSkipped", withCompression(test.compression))
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/articles", nil)
+			req.Header.Set(HeaderAcceptEncoding, "gzip")
+			resp := httptest.NewRecorder()
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Empty(t, resp.Header().Get(HeaderContentEncoding))
+			assert.NotContains(t, resp.Header()[HeaderVary], HeaderAcceptEncoding)
+
+			var list ArticlesResponse
+			assert.Nil(t, json.NewDecoder(resp.Body).Decode(&list))
+		})
+	}
+}
+
+func TestCompressVaryWithoutAcceptEncoding(t *testing.T) {
+	app := newTestApp("TestCompressVaryWithoutAcceptEncoding", withCompression(&Compression{ContentTypes: []string{ContentTypeJSON}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Empty(t, resp.Header().Get(HeaderContentEncoding))
+	assert.Contains(t, resp.Header()[HeaderVary], HeaderAcceptEncoding)
+}
+
+func TestCompressETags(t *testing.T) {
+	app := newTestApp("TestCompressETags", withCompression(&Compression{ContentTypes: []string{ContentTypeJSON}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+	plainETag := resp.Header().Get(HeaderETag)
+
+	req = httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+	gzipETag := resp.Header().Get(HeaderETag)
+
+	assert.Equal(t, EncodingGzip, resp.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, strings.TrimSuffix(plainETag, `"`)+`-gzip"`, gzipETag)
+
+	req = httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAcceptEncoding, "gzip")
+	req.Header.Set(HeaderIfNoneMatch, gzipETag)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Equal(t, gzipETag, resp.Header().Get(HeaderETag))
+	assert.Empty(t, resp.Body.Bytes())
+
+	body, _ := json.Marshal(Article{Title: "updated article", Date: "2020-02-01", Body: "updated body", Tags: []string{"science"}})
+	req = httptest.NewRequest(http.MethodPut, "/v2/articles/1", bytes.NewReader(body))
+	req.Header.Set(HeaderIfMatch, gzipETag)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestDecodedETags(t *testing.T) {
+	assert.Equal(t, `"abc", W/"def", "ghi"`, decodedETags(`"abc-gzip", W/"def-deflate", "ghi"`))
+	assert.Equal(t, `W/"abc-gzip"`, encodedETag(`W/"abc"`, EncodingGzip))
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"rest-article/database/model"
+	"rest-article/repo"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Conditional request header constants
+const (
+	HeaderETag            = "ETag"
+	HeaderLastModified    = "Last-Modified"
+	HeaderIfMatch         = "If-Match"
+	HeaderIfNoneMatch     = "If-None-Match"
+	HeaderIfModifiedSince = "If-Modified-Since"
+)
+
+// strongETag returns a strong entity tag for the bytes of a representation
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// articleETag returns the strong entity tag of an article representation. It
+// leads with the article version, so that If-Match can be checked against the
+// latest version whichever representation the client read.
+func articleETag(version int, body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%d-%s"`, version, hex.EncodeToString(sum[:8]))
+}
+
+// articleVersionMatches reports whether the If-Match header value names an
+// entity tag of the article version. If-Match uses strong comparison, weak
+// tags never match.
+func articleVersionMatches(header string, version int) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.Trim(candidate, `"`), "-", 2)
+		if taggedVersion, err := strconv.Atoi(parts[0]); err == nil && taggedVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches reports whether the If-Match or If-None-Match header value
+// names the entity tag. Weak comparison ignores the W/ prefix, as required
+// for If-None-Match.
+func etagMatches(header, etag string, weak bool) bool {
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether the copy the client holds is still current.
+// If-None-Match takes precedence over If-Modified-Since, which is only
+// compared to the second as HTTP dates carry no more.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get(HeaderIfNoneMatch); match != "" {
+		return etagMatches(match, etag, true)
+	}
+
+	if since := r.Header.Get(HeaderIfModifiedSince); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// writeConditional writes the body along with its validators, or a bare 304
+// when the client already holds it. A zero lastModified leaves out the
+// Last-Modified header.
+func (app *App) writeConditional(w http.ResponseWriter, r *http.Request, contentType string, body []byte, etag string, lastModified time.Time) {
+
+	w.Header().Set(HeaderETag, etag)
+	if !lastModified.IsZero() {
+		w.Header().Set(HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set(HeaderContentType, contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		app.logger.Errorf("error sending response because: %v", err)
+		return
+	}
+}
+
+// writeArticleResponse writes the article in the negotiated representation,
+// tagged with the version and update time of the stored article.
+func (app *App) writeArticleResponse(w http.ResponseWriter, r *http.Request, response Article, article *model.Article) {
+
+	contentType, body, ok := app.encodeResponse(w, r, response)
+	if !ok {
+		return
+	}
+
+	app.writeConditional(w, r, contentType, body, articleETag(article.Version, body), article.UpdatedAt)
+}
+
+// checkIfMatch checks the If-Match header of a request changing the article
+// against its current version, a missing header is a 428 so no change
+// overwrites another unseen. Any problem is written to w and reported as false.
+func (app *App) checkIfMatch(w http.ResponseWriter, r *http.Request, article *model.Article) bool {
+
+	header := r.Header.Get(HeaderIfMatch)
+	if header == "" {
+		err := handleError(w, "no If-Match header provided, send the ETag of the article", http.StatusPreconditionRequired)
+		if err != nil {
+			app.logger.Errorf("no If-Match header provided")
+		}
+		return false
+	}
+
+	if !articleVersionMatches(header, article.Version) {
+		err := handleError(w, repo.ErrVersionConflict.Error(), http.StatusPreconditionFailed)
+		if err != nil {
+			app.logger.Errorf("article %d has been changed since it was read", article.Id)
+		}
+		return false
+	}
+
+	return true
+}
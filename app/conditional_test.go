@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetArticleFunctionETag(t *testing.T) {
+	app := newTestApp("TestGetArticleFunctionETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	etag := resp.Header().Get(HeaderETag)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, strings.HasPrefix(etag, `"1-`))
+	assert.Equal(t, "Sun, 01 Mar 2020 12:00:00 GMT", resp.Header().Get(HeaderLastModified))
+
+	req = httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderIfNoneMatch, etag)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.String())
+
+	// every representation has its own tag
+	req = httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAccept, ContentTypeXML)
+	req.Header.Set(HeaderIfNoneMatch, etag)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get(HeaderETag))
+}
+
+func TestPutArticleFunctionIfMatch(t *testing.T) {
+	body, _ := json.Marshal(Article{
+		Title: "updated article",
+		Date:  "2020-02-01",
+		Body:  "updated body",
+		Tags:  []string{"science"},
+	})
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+	}{
+		{"missing", "", http.StatusPreconditionRequired},
+		{"stale", `"2-0000000000000000"`, http.StatusPreconditionFailed},
+		{"weak", `W/"1-0000000000000000"`, http.StatusPreconditionFailed},
+		{"current", `"1-0000000000000000"`, http.StatusOK},
+		{"any", "*", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestPutArticleFunctionIfMatch")
+
+			req := httptest.NewRequest(http.MethodPut, "/articles/1", bytes.NewReader(body))
+			if test.ifMatch != "" {
+				req.Header.Set(HeaderIfMatch, test.ifMatch)
+			}
+			resp := httptest.NewRecorder()
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.status, resp.Code)
+		})
+	}
+}
+
+func TestRestoreRevisionFunctionIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+	}{
+		{"missing", "", http.StatusPreconditionRequired},
+		{"stale", `"2-0000000000000000"`, http.StatusPreconditionFailed},
+		{"current", `"1-0000000000000000"`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestRestoreRevisionFunctionIfMatch")
+
+			req := httptest.NewRequest(http.MethodPost, "/articles/1/revisions/1/restore", nil)
+			if test.ifMatch != "" {
+				req.Header.Set(HeaderIfMatch, test.ifMatch)
+			}
+			resp := httptest.NewRecorder()
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.status, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CORS header constants
+const (
+	HeaderOrigin                        = "Origin"
+	HeaderVary                          = "Vary"
+	HeaderAllow                         = "Allow"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// defaultCORSHeaders are the request headers allowed when none are configured
+var defaultCORSHeaders = []string{HeaderContentType, HeaderAuthor, "Authorization", "X-API-Key", HeaderIfMatch, HeaderIfNoneMatch}
+
+// CORS is the cross origin policy of the API. An origin of "*" allows every
+// origin without credentials, empty methods allow every method of a route.
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// SetCORS applies the cross origin policy to every route, nil turns CORS off
+func (app *App) SetCORS(cors *CORS) {
+	app.runtime.update(func(runtime *Runtime) { runtime.CORS = cors })
+}
+
+// allowOrigin returns the value of Access-Control-Allow-Origin for the origin
+// or an empty string when the origin is not allowed.
+func (cors *CORS) allowOrigin(origin string) string {
+	for _, allowed := range cors.AllowedOrigins {
+		if allowed == "*" {
+			// the origin is never echoed for the wildcard, that would let any
+			// site read responses with the credentials of its visitors
+			return "*"
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// allowMethods returns the methods of a route that cross origin requests may use
+func (cors *CORS) allowMethods(routeMethods []string) []string {
+	if len(cors.AllowedMethods) == 0 {
+		return routeMethods
+	}
+
+	var methods []string
+	for _, method := range routeMethods {
+		for _, allowed := range cors.AllowedMethods {
+			if strings.EqualFold(allowed, method) {
+				methods = append(methods, method)
+				break
+			}
+		}
+	}
+	return methods
+}
+
+// corsHeaders adds the response headers of cross origin requests
+func (app *App) corsHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cors := app.runtime.load().CORS
+		origin := r.Header.Get(HeaderOrigin)
+		if cors == nil || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add(HeaderVary, HeaderOrigin)
+		if allowed := cors.allowOrigin(origin); allowed != "" {
+			w.Header().Set(HeaderAccessControlAllowOrigin, allowed)
+			// browsers refuse credentials with a wildcard origin
+			if cors.AllowCredentials && allowed != "*" {
+				w.Header().Set(HeaderAccessControlAllowCredentials, "true")
+			}
+			if len(cors.ExposedHeaders) > 0 {
+				w.Header().Set(HeaderAccessControlExposeHeaders, strings.Join(cors.ExposedHeaders, ", "))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setupPreflight registers an OPTIONS route for every path of the router, it
+// answers CORS preflight requests and lists the methods of the path.
+func (app *App) setupPreflight() {
+	var paths []string
+	methods := make(map[string][]string)
+
+	_ = app.Router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		if _, ok := methods[path]; !ok {
+			paths = append(paths, path)
+		}
+		methods[path] = append(methods[path], routeMethods...)
+		return nil
+	})
+
+	for _, path := range paths {
+		app.Router.
+			Methods("OPTIONS").
+			Path(path).
+			HandlerFunc(app.preflightFunction(append(methods[path], http.MethodOptions)))
+	}
+}
+
+func (app *App) preflightFunction(routeMethods []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderAllow, strings.Join(routeMethods, ", "))
+
+		cors := app.runtime.load().CORS
+		requestMethod := r.Header.Get(HeaderAccessControlRequestMethod)
+		if cors == nil || requestMethod == "" || w.Header().Get(HeaderAccessControlAllowOrigin) == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		methods := cors.allowMethods(routeMethods)
+		if !containsFold(methods, requestMethod) {
+			err := handleError(w, fmt.Sprintf("method %s is not allowed", requestMethod), http.StatusForbidden)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		headers := cors.AllowedHeaders
+		if len(headers) == 0 {
+			headers = defaultCORSHeaders
+		}
+
+		w.Header().Add(HeaderVary, HeaderAccessControlRequestMethod)
+		w.Header().Add(HeaderVary, HeaderAccessControlRequestHeaders)
+		w.Header().Set(HeaderAccessControlAllowMethods, strings.Join(methods, ", "))
+		w.Header().Set(HeaderAccessControlAllowHeaders, strings.Join(headers, ", "))
+		if cors.MaxAge > 0 {
+			w.Header().Set(HeaderAccessControlMaxAge, fmt.Sprintf("%d", int(cors.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
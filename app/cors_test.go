@@ -0,0 +1,102 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	app := newTestApp("TestCORSPreflight", withCORS(&CORS{
+		AllowedOrigins:   []string{"https://editor.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/articles/1", nil)
+	req.Header.Set(HeaderOrigin, "https://editor.example.com")
+	req.Header.Set(HeaderAccessControlRequestMethod, http.MethodPut)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "https://editor.example.com", resp.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", resp.Header().Get(HeaderAccessControlAllowCredentials))
+	assert.Equal(t, "GET, PUT, DELETE, OPTIONS", resp.Header().Get(HeaderAccessControlAllowMethods))
+	assert.Equal(t, "600", resp.Header().Get(HeaderAccessControlMaxAge))
+	assert.Contains(t, resp.Header().Get(HeaderAccessControlAllowHeaders), "X-API-Key")
+}
+
+func TestCORSPreflightMethodNotAllowed(t *testing.T) {
+	app := newTestApp("TestCORSPreflightMethodNotAllowed", withCORS(&CORS{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet},
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/articles", nil)
+	req.Header.Set(HeaderOrigin, "https://editor.example.com")
+	req.Header.Set(HeaderAccessControlRequestMethod, http.MethodPost)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestCORSUnknownOrigin(t *testing.T) {
+	app := newTestApp("TestCORSUnknownOrigin", withCORS(&CORS{
+		AllowedOrigins: []string{"https://editor.example.com"},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set(HeaderOrigin, "https://evil.example.com")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, HeaderOrigin, resp.Header().Get(HeaderVary))
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	app := newTestApp("TestCORSWildcardOrigin", withCORS(&CORS{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{HeaderRateLimitRemaining},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set(HeaderOrigin, "https://editor.example.com")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "*", resp.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, HeaderRateLimitRemaining, resp.Header().Get(HeaderAccessControlExposeHeaders))
+}
+
+func TestCORSWildcardOriginWithoutCredentials(t *testing.T) {
+	app := newTestApp("TestCORSWildcardOriginWithoutCredentials", withCORS(&CORS{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set(HeaderOrigin, "https://evil.example.com")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, "*", resp.Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Empty(t, resp.Header().Get(HeaderAccessControlAllowCredentials))
+}
+
+func TestOptionsWithoutCORS(t *testing.T) {
+	app := newTestApp("TestOptionsWithoutCORS")
+
+	req := httptest.NewRequest(http.MethodOptions, "/authors/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "GET, PUT, DELETE, OPTIONS", resp.Header().Get(HeaderAllow))
+}
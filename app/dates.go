@@ -0,0 +1,93 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DateFormat is the ISO-8601 calendar date used for article dates, timestamps
+// use RFC 3339.
+const DateFormat = "2006-01-02"
+
+// localTimestampFormat is an ISO-8601 timestamp without offset, it is read in
+// the timezone of the request.
+const localTimestampFormat = "2006-01-02T15:04:05"
+
+// legacyDateFormats are still accepted on input while clients move over to
+// DateFormat, 01-02-2006 is the old output format and 20060102 the old tag
+// route format.
+var legacyDateFormats = []string{"01-02-2006", "20060102"}
+
+var errBadDate = errors.New("bad date format provided")
+
+// requestLocation returns the timezone named by the tz parameter of the request,
+// UTC when there is none. Day boundaries and returned timestamps follow it.
+func requestLocation(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %s provided", tz)
+	}
+
+	return loc, nil
+}
+
+// parseDate reads a calendar date. RFC 3339 and ISO-8601 timestamps are taken
+// as the day they fall on in loc, legacy formats are accepted as well.
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	if date, err := time.Parse(DateFormat, value); err == nil {
+		return date, nil
+	}
+
+	if timestamp, err := parseTimestamp(value, loc); err == nil {
+		year, month, day := timestamp.In(loc).Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	for _, format := range legacyDateFormats {
+		if date, err := time.Parse(format, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, errBadDate
+}
+
+// parseTimestamp reads an RFC 3339 timestamp, timestamps without an offset are
+// read in loc.
+func parseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp, nil
+	}
+
+	return time.ParseInLocation(localTimestampFormat, value, loc)
+}
+
+func formatDate(date time.Time) string {
+	return date.Format(DateFormat)
+}
+
+func formatTimestamp(timestamp time.Time, loc *time.Location) string {
+	return timestamp.In(loc).Format(time.RFC3339)
+}
+
+// locationFromRequest is requestLocation for handlers, a bad timezone is written
+// to w and reported as false.
+func (app *App) locationFromRequest(w http.ResponseWriter, r *http.Request) (*time.Location, bool) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking timezone because: %v", err)
+		}
+		return nil, false
+	}
+
+	return loc, true
+}
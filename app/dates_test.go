@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no timezone database: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		loc   *time.Location
+		date  string
+	}{
+		{"iso date", "2020-04-20", time.UTC, "2020-04-20"},
+		{"iso date ignores timezone", "2020-04-20", newYork, "2020-04-20"},
+		{"rfc 3339 in utc", "2020-04-21T02:00:00Z", time.UTC, "2020-04-21"},
+		{"rfc 3339 in caller timezone", "2020-04-21T02:00:00Z", newYork, "2020-04-20"},
+		{"local timestamp", "2020-04-20T23:30:00", newYork, "2020-04-20"},
+		{"legacy output format", "04-20-2020", time.UTC, "2020-04-20"},
+		{"legacy tag format", "20200420", time.UTC, "2020-04-20"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, err := parseDate(test.value, test.loc)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.date, formatDate(date))
+		})
+	}
+
+	_, err = parseDate("20-04-2020", time.UTC)
+	assert.Equal(t, errBadDate, err)
+}
+
+func TestGetTagsFunctionISODate(t *testing.T) {
+	app := newTestApp("TestGetTagsFunctionISODate")
+
+	req := httptest.NewRequest(http.MethodGet, "/tag/test/2020-04-20?tz=Europe/London", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestGetTagsFunctionUnknownTimezone(t *testing.T) {
+	app := newTestApp("TestGetTagsFunctionUnknownTimezone")
+
+	req := httptest.NewRequest(http.MethodGet, "/tag/test/2020-04-20?tz=Mars/Olympus", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, ResponseError("unknown timezone Mars/Olympus provided"), respBody.Error)
+}
+
+func TestGetRevisionFunctionTimezone(t *testing.T) {
+	app := newTestApp("TestGetRevisionFunctionTimezone")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1/revisions/1?tz=Asia/Tokyo", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody Revision
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	createdAt, err := time.Parse(time.RFC3339, respBody.CreatedAt)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, err)
+	_, offset := createdAt.Zone()
+	assert.Equal(t, 9*60*60, offset)
+}
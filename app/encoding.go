@@ -0,0 +1,268 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media type constants
+const (
+	ContentTypeXML  = "application/xml"
+	ContentTypeYAML = "application/yaml"
+	ContentTypeCSV  = "text/csv"
+)
+
+// mediaTypeAliases maps other names clients use onto the media types served
+var mediaTypeAliases = map[string]string{
+	"text/xml":           ContentTypeXML,
+	"application/x-yaml": ContentTypeYAML,
+	"text/yaml":          ContentTypeYAML,
+}
+
+// vendorSuffixes maps the structured syntax suffix of a vendor media type,
+// such as +xml in application/vnd.rest-article.v2+xml, onto its media type.
+var vendorSuffixes = map[string]string{
+	"":      ContentTypeJSON,
+	"+json": ContentTypeJSON,
+	"+xml":  ContentTypeXML,
+	"+yaml": ContentTypeYAML,
+	"+csv":  ContentTypeCSV,
+}
+
+// encoders write a response in each of the media types served
+var encoders = map[string]func(w io.Writer, response interface{}) error{
+	ContentTypeJSON: func(w io.Writer, response interface{}) error {
+		return json.NewEncoder(w).Encode(response)
+	},
+	ContentTypeXML: func(w io.Writer, response interface{}) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		return xml.NewEncoder(w).Encode(response)
+	},
+	ContentTypeYAML: func(w io.Writer, response interface{}) error {
+		return yaml.NewEncoder(w).Encode(response)
+	},
+	ContentTypeCSV: func(w io.Writer, response interface{}) error {
+		records, ok := response.(csvRecords)
+		if !ok {
+			return fmt.Errorf("%T can not be written as csv", response)
+		}
+		writer := csv.NewWriter(w)
+		return writer.WriteAll(records.csvRecords())
+	},
+}
+
+// csvRecords is implemented by the responses that can be flattened into csv,
+// the first record is the header.
+type csvRecords interface {
+	csvRecords() [][]string
+}
+
+// csvListSeparator joins list values within a single csv field
+const csvListSeparator = ";"
+
+var articleCSVHeader = []string{"id", "title", "date", "body", "tags", "status", "publish_at", "authors"}
+
+func (article Article) csvRecord() []string {
+	var authors []string
+	for _, author := range article.Authors {
+		authors = append(authors, author.Name)
+	}
+
+	return []string{
+		article.Id,
+		article.Title,
+		article.Date,
+		article.Body,
+		strings.Join(article.Tags, csvListSeparator),
+		article.Status,
+		article.PublishAt,
+		strings.Join(authors, csvListSeparator),
+	}
+}
+
+func (article Article) csvRecords() [][]string {
+	return [][]string{articleCSVHeader, article.csvRecord()}
+}
+
+func (response ArticlesResponse) csvRecords() [][]string {
+	records := [][]string{articleCSVHeader}
+	for _, article := range response.Articles {
+		records = append(records, article.csvRecord())
+	}
+	return records
+}
+
+func (response TagSummaryResponse) csvRecords() [][]string {
+	return [][]string{
+		{"tag", "count", "articles", "related_tags"},
+		{response.Tag, strconv.Itoa(response.Count), strings.Join(response.Articles, csvListSeparator), strings.Join(response.RelatedTags, csvListSeparator)},
+	}
+}
+
+func (response TagSummaryResponseV1) csvRecords() [][]string {
+	records := TagSummaryResponse(response).csvRecords()
+	records[0][3] = "related_tag"
+	return records
+}
+
+// writeResponse writes the response in the media type negotiated from the
+// Accept header, JSON when the client has no preference. Clients accepting
+// none of the media types the response can be written in get a 406.
+func (app *App) writeResponse(w http.ResponseWriter, r *http.Request, statusCode int, response interface{}) {
+
+	contentType, body, ok := app.encodeResponse(w, r, response)
+	if !ok {
+		return
+	}
+
+	w.Header().Add(HeaderContentType, contentType)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		app.logger.Errorf("error sending response because: %v", err)
+		return
+	}
+}
+
+// encodeResponse encodes the response in the media type negotiated from the
+// Accept header, any problem is written to w and reported as false.
+func (app *App) encodeResponse(w http.ResponseWriter, r *http.Request, response interface{}) (string, []byte, bool) {
+
+	contentType, ok := app.acceptable(w, r, response)
+	if !ok {
+		return "", nil, false
+	}
+
+	var buf bytes.Buffer
+	if err := encoders[contentType](&buf, response); err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return "", nil, false
+	}
+
+	return contentType, buf.Bytes(), true
+}
+
+// acceptable negotiates the media type of the response from the Accept header.
+// Handlers changing data call it with their zero response before any change,
+// so a client accepting none of the media types gets a 406 for a request that
+// did nothing. The 406 is written to w and reported as false.
+func (app *App) acceptable(w http.ResponseWriter, r *http.Request, response interface{}) (string, bool) {
+
+	offers := []string{ContentTypeJSON, ContentTypeXML, ContentTypeYAML}
+	if _, ok := response.(csvRecords); ok {
+		offers = append(offers, ContentTypeCSV)
+	}
+
+	addVary(w, HeaderAccept)
+
+	contentType, ok := negotiateContentType(r.Header.Get(HeaderAccept), offers)
+	if !ok {
+		err := handleError(w, fmt.Sprintf("none of the accepted media types are available, expected one of %s", strings.Join(offers, ", ")), http.StatusNotAcceptable)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return "", false
+	}
+
+	return contentType, true
+}
+
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateContentType picks the offer the Accept header prefers, the first
+// offer when it is empty, and reports false when no offer is acceptable. Media
+// types given a quality of 0 are never picked, not even through a wildcard.
+func negotiateContentType(accept string, offers []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0], true
+	}
+
+	var ranges []mediaRange
+	excluded := make(map[string]bool)
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if match := vendorMediaType.FindStringSubmatch(mediaType); match != nil {
+			vendorType, ok := vendorSuffixes[match[2]]
+			if !ok {
+				continue
+			}
+			mediaType = vendorType
+		} else if alias, ok := mediaTypeAliases[mediaType]; ok {
+			mediaType = alias
+		}
+
+		if quality <= 0 {
+			excluded[mediaType] = true
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, mediaRange := range ranges {
+		for _, offer := range offers {
+			if !excluded[offer] && matchesMediaRange(mediaRange.mediaType, offer) {
+				return offer, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchesMediaRange reports whether the media type falls in the range, which
+// may be */* or a type/* wildcard.
+func matchesMediaRange(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	return false
+}
+
+// addVary adds the header to the Vary header of the response unless it is
+// already there.
+func addVary(w http.ResponseWriter, header string) {
+	for _, value := range w.Header().Values(HeaderVary) {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), header) {
+				return
+			}
+		}
+	}
+	w.Header().Add(HeaderVary, header)
+}
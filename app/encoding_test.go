@@ -0,0 +1,217 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/database/model"
+	"rest-article/search"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{ContentTypeJSON, ContentTypeXML, ContentTypeYAML, ContentTypeCSV}
+
+	tests := []struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		{"", ContentTypeJSON, true},
+		{"*/*", ContentTypeJSON, true},
+		{"application/json", ContentTypeJSON, true},
+		{"application/xml", ContentTypeXML, true},
+		{"text/xml", ContentTypeXML, true},
+		{"application/x-yaml", ContentTypeYAML, true},
+		{"text/csv", ContentTypeCSV, true},
+		{"text/*", ContentTypeCSV, true},
+		{"text/csv;q=0.5, application/xml", ContentTypeXML, true},
+		{"application/json;q=0, application/*", ContentTypeXML, true},
+		{"application/vnd.rest-article.v2+xml", ContentTypeXML, true},
+		{"application/vnd.rest-article.v2", ContentTypeJSON, true},
+		{"application/json; version=2", ContentTypeJSON, true},
+		{"text/html", "", false},
+		{"application/json;q=0", "", false},
+	}
+
+	for _, test := range tests {
+		contentType, ok := negotiateContentType(test.accept, offers)
+		assert.Equal(t, test.ok, ok, test.accept)
+		assert.Equal(t, test.expected, contentType, test.accept)
+	}
+
+	_, ok := negotiateContentType("text/csv", offers[:3])
+	assert.False(t, ok)
+}
+
+func TestGetArticleFunctionXML(t *testing.T) {
+	app := newTestApp("TestGetArticleFunctionXML")
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAccept, ContentTypeXML)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeXML, resp.Header().Get(HeaderContentType))
+	assert.Equal(t, HeaderAccept, resp.Header().Get(HeaderVary))
+
+	var article Article
+	assert.NoError(t, xml.NewDecoder(resp.Body).Decode(&article))
+	assert.Equal(t, "article", article.XMLName.Local)
+	assert.Equal(t, "1", article.Id)
+	assert.NotEmpty(t, article.Tags)
+}
+
+func TestGetArticlesFunctionCSV(t *testing.T) {
+	app := newTestApp("TestGetArticlesFunctionCSV")
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles?limit=2", nil)
+	req.Header.Set(HeaderAccept, ContentTypeCSV)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeCSV, resp.Header().Get(HeaderContentType))
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.True(t, len(records) > 1)
+	assert.Equal(t, articleCSVHeader, records[0])
+	assert.Equal(t, "2020-02-01", records[1][2])
+}
+
+func TestGetTagsFunctionYAML(t *testing.T) {
+	app := newTestApp("TestGetTagsFunctionYAML")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tag/science/2020-02-01", nil)
+	req.Header.Set(HeaderAccept, ContentTypeYAML)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeYAML, resp.Header().Get(HeaderContentType))
+
+	var summary map[string]interface{}
+	assert.NoError(t, yaml.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal(t, "science", summary["tag"])
+	assert.Contains(t, summary, "related_tag")
+	assert.NotContains(t, summary, "xmlname")
+}
+
+func TestGetArticleFunctionNotAcceptable(t *testing.T) {
+	app := newTestApp("TestGetArticleFunctionNotAcceptable")
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAccept, "text/html")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+
+	var respBody ErrorResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+	assert.Contains(t, string(respBody.Error), ContentTypeCSV)
+}
+
+func TestPostArticleFunctionXML(t *testing.T) {
+	app := newTestApp("TestPostArticleFunctionXML")
+
+	body := `{"id": "1", "title": "test article", "date": "2020-02-01", "body": "test art", "tags": ["science"]}`
+	req := httptest.NewRequest(http.MethodPost, "/v2/articles", strings.NewReader(body))
+	req.Header.Set(HeaderAccept, ContentTypeXML)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, ContentTypeXML, resp.Header().Get(HeaderContentType))
+
+	var respBody CreateArticleResponse
+	assert.NoError(t, xml.NewDecoder(resp.Body).Decode(&respBody))
+	assert.Equal(t, "created", respBody.XMLName.Local)
+	assert.True(t, respBody.Success)
+}
+
+func TestPostArticlesBatchFunctionYAML(t *testing.T) {
+	app := newTestApp("TestPostArticlesBatchFunctionYAML")
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/articles:batch", strings.NewReader(importJSONL))
+	req.Header.Set(HeaderContentType, ContentTypeJSONL)
+	req.Header.Set(HeaderAccept, ContentTypeYAML)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeYAML, resp.Header().Get(HeaderContentType))
+
+	var respBody ImportResponse
+	assert.NoError(t, yaml.NewDecoder(resp.Body).Decode(&respBody))
+	assert.True(t, respBody.Success)
+	assert.Equal(t, 3, respBody.Imported)
+}
+
+func TestWriteFunctionsNotAcceptable(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/v2/articles", `{"id": "1", "title": "test article", "date": "2020-02-01", "body": "test art"}`},
+		{http.MethodPost, "/v2/articles:batch", importJSONL},
+		{http.MethodPut, "/v2/articles/1", `{"id": "1", "title": "test article", "date": "2020-02-01", "body": "test art"}`},
+		{http.MethodDelete, "/v2/articles/1", ""},
+		{http.MethodPost, "/v2/articles/1/revisions/1/restore", ""},
+		{http.MethodPost, "/v2/authors", `{"name": "author"}`},
+		{http.MethodPost, "/v2/admin/articles/1/restore", ""},
+		{http.MethodPost, "/v2/admin/tags/test/merge", `{"into": "science"}`},
+		{http.MethodPost, "/v2/admin/keys", `{"name": "key", "scopes": ["articles:read"]}`},
+		{http.MethodDelete, "/v2/admin/keys/1", ""},
+	}
+
+	app := newTestApp("TestWriteFunctionsNotAcceptable")
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			req.Header.Set(HeaderContentType, ContentTypeJSONL)
+			req.Header.Set(HeaderAccept, "text/html")
+			resp := httptest.NewRecorder()
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+
+			var respBody ErrorResponse
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+			assert.Contains(t, string(respBody.Error), ContentTypeYAML)
+		})
+	}
+}
+
+func TestDeleteArticleFunctionNotAcceptable(t *testing.T) {
+	index := search.NewMemoryIndex()
+	_ = index.Index(model.Article{
+		Id:    1,
+		Title: "test article",
+		Date:  time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Body:  "test article",
+	}, []string{"test"})
+
+	app := newTestApp("TestDeleteArticleFunctionNotAcceptable", withIndex(index))
+
+	req := httptest.NewRequest(http.MethodDelete, "/v2/articles/1", nil)
+	req.Header.Set(HeaderAccept, "text/html")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+
+	// the article was left alone
+	results, _ := index.Search(search.Query{Text: "test"})
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/url"
+	"rest-article/database/model"
+	"rest-article/feed"
+	"time"
+)
+
+// Feed format constants
+const (
+	FeedAtom = "atom"
+	FeedRSS  = "rss"
+)
+
+// Feed media type constants
+const (
+	ContentTypeAtom = "application/atom+xml"
+	ContentTypeRSS  = "application/rss+xml"
+)
+
+// HeaderForwardedProto names the scheme the client used in front of a proxy
+const HeaderForwardedProto = "X-Forwarded-Proto"
+
+// feedSize is the number of newest articles a feed carries
+const feedSize = 20
+
+// feedEpoch dates the tag URIs of the feeds themselves and stands in as the
+// updated time of empty feeds.
+var feedEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// feedAuthor is the author of the feeds themselves, Atom requires one for
+// entries without authors of their own.
+const feedAuthor = "rest-article"
+
+// feedFunction serves the newest published articles, of a single tag when the
+// path names one, as an Atom or RSS feed.
+func (app *App) feedFunction(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		tagName := mux.Vars(r)["tagName"]
+
+		articles, err := app.repo.ListArticles(model.ArticleFilter{Tag: tagName, Limit: feedSize})
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		var ids []int
+		for _, article := range articles {
+			ids = append(ids, article.Id)
+		}
+
+		tags, err := app.repo.GetArticleTags(ids)
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		authors, err := app.repo.GetArticleAuthors(ids)
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		baseURL := requestBaseURL(r)
+		authority := baseURL.Hostname()
+
+		articlesFeed := &feed.Feed{
+			ID:          feed.TagURI(authority, feedEpoch, r.URL.Path),
+			Title:       "Articles",
+			Description: "The newest articles",
+			Link:        baseURL.String() + "/v2/articles",
+			Self:        baseURL.String() + r.URL.Path,
+			Author:      feedAuthor,
+			Updated:     feedEpoch,
+		}
+		if tagName != "" {
+			articlesFeed.Title = fmt.Sprintf("Articles tagged %s", tagName)
+			articlesFeed.Description = fmt.Sprintf("The newest articles tagged %s", tagName)
+			articlesFeed.Link = baseURL.String() + "/v2/articles?tag=" + url.QueryEscape(tagName)
+		}
+
+		var lastModified time.Time
+		for _, article := range articles {
+			published := articlePublished(article)
+			updated := published
+			if article.UpdatedAt.After(updated) {
+				updated = article.UpdatedAt
+			}
+			if updated.After(lastModified) {
+				lastModified = updated
+			}
+
+			var names []string
+			for _, author := range authors[article.Id] {
+				names = append(names, author.Name)
+			}
+
+			articlesFeed.Entries = append(articlesFeed.Entries, feed.Entry{
+				ID:         feed.TagURI(authority, article.Date, fmt.Sprintf("/articles/%d", article.Id)),
+				Title:      article.Title,
+				Link:       fmt.Sprintf("%s/v2/articles/%d", baseURL.String(), article.Id),
+				Content:    article.Body,
+				Authors:    names,
+				Categories: tags[article.Id],
+				Published:  published,
+				Updated:    updated,
+			})
+		}
+		if !lastModified.IsZero() {
+			articlesFeed.Updated = lastModified
+		}
+
+		contentType := ContentTypeAtom
+		write := articlesFeed.Atom
+		if format == FeedRSS {
+			contentType = ContentTypeRSS
+			write = articlesFeed.RSS
+		}
+
+		body, err := write()
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		app.writeConditional(w, r, contentType, body, strongETag(body), lastModified)
+	}
+}
+
+// articlePublished returns when the article was published, its publishing
+// time or failing that its date.
+func articlePublished(article *model.Article) time.Time {
+	if article.PublishAt != nil {
+		return *article.PublishAt
+	}
+	return article.Date
+}
+
+// requestBaseURL returns the scheme and host the client reached the service
+// on, for the absolute links feeds need.
+func requestBaseURL(r *http.Request) *url.URL {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get(HeaderForwardedProto) == "https" {
+		scheme = "https"
+	}
+
+	return &url.URL{Scheme: scheme, Host: r.Host}
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAtomFeedFunction(t *testing.T) {
+	app := newTestApp("TestAtomFeedFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/feeds/articles.atom", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeAtom, resp.Header().Get(HeaderContentType))
+	assert.NotEmpty(t, resp.Header().Get(HeaderETag))
+	assert.Equal(t, "Mon, 03 Feb 2020 00:00:00 GMT", resp.Header().Get(HeaderLastModified))
+
+	body := resp.Body.String()
+	assert.Contains(t, body, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, body, `<id>tag:example.com,2020-02-01:/articles/1</id>`)
+	assert.Contains(t, body, `<updated>2020-02-03T00:00:00Z</updated>`)
+	assert.Contains(t, body, `<category term="test2"></category>`)
+	assert.Contains(t, body, `<link href="http://example.com/v2/articles/1" rel="alternate"></link>`)
+}
+
+func TestTagRSSFeedFunction(t *testing.T) {
+	app := newTestApp("TestTagRSSFeedFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/feeds/tag/science.rss", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ContentTypeRSS, resp.Header().Get(HeaderContentType))
+
+	body := resp.Body.String()
+	assert.Contains(t, body, `<title>Articles tagged science</title>`)
+	assert.Contains(t, body, `<guid isPermaLink="false">tag:example.com,2020-02-01:/articles/1</guid>`)
+	assert.Contains(t, body, `<category>test</category>`)
+}
+
+func TestFeedFunctionConditional(t *testing.T) {
+	app := newTestApp("TestFeedFunctionConditional")
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/tag/science.atom", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+	etag := resp.Header().Get(HeaderETag)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/tag/science.atom", nil)
+	req.Header.Set(HeaderIfNoneMatch, etag)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Equal(t, etag, resp.Header().Get(HeaderETag))
+	assert.Empty(t, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/tag/science.atom", nil)
+	req.Header.Set(HeaderIfModifiedSince, "Mon, 03 Feb 2020 00:00:00 GMT")
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/tag/science.atom", nil)
+	req.Header.Set(HeaderIfModifiedSince, "Sun, 02 Feb 2020 00:00:00 GMT")
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/tag/science.atom", nil)
+	req.Header.Set(HeaderIfNoneMatch, `"stale"`)
+	req.Header.Set(HeaderIfModifiedSince, "Mon, 03 Feb 2020 00:00:00 GMT")
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"a", "b"`, `"b"`, false))
+	assert.True(t, etagMatches(`*`, `"b"`, false))
+	assert.True(t, etagMatches(`W/"b"`, `"b"`, true))
+	assert.False(t, etagMatches(`W/"b"`, `"b"`, false))
+	assert.False(t, etagMatches(`"a"`, `"b"`, true))
+}
@@ -0,0 +1,551 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"rest-article/auth"
+	"rest-article/database/model"
+	"rest-article/policy"
+	"rest-article/repo"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Import format constants
+const (
+	ImportFormatJSONL = "jsonl"
+	ImportFormatCSV   = "csv"
+)
+
+// ContentTypeJSONL is the media type of newline delimited JSON
+const ContentTypeJSONL = "application/x-ndjson"
+
+// importFormats maps the media types an import is accepted in onto its format
+var importFormats = map[string]string{
+	ContentTypeJSONL:      ImportFormatJSONL,
+	"application/jsonl":   ImportFormatJSONL,
+	"application/x-jsonl": ImportFormatJSONL,
+	ContentTypeCSV:        ImportFormatCSV,
+}
+
+// Import error handling constants, abort stops the import at the first bad
+// record and skip carries on without it
+const (
+	OnErrorAbort = "abort"
+	OnErrorSkip  = "skip"
+)
+
+// DefaultImportBatchSize is the number of articles written per statement
+const DefaultImportBatchSize = 500
+
+// articleImportCSVHeader lists the columns a csv import may hold, the authors
+// of an article are given by id.
+var articleImportCSVHeader = []string{"id", "title", "date", "body", "tags", "status", "publish_at", "author_ids"}
+
+// ImportOptions controls a bulk import of articles
+type ImportOptions struct {
+	// Format is ImportFormatJSONL or ImportFormatCSV
+	Format string
+	// SkipInvalid imports the good records around bad ones, otherwise the
+	// import is written in one transaction the first bad record rolls back
+	SkipInvalid bool
+	// BatchSize is the number of articles written at once, 0 picks
+	// DefaultImportBatchSize
+	BatchSize int
+	// Author is recorded against the first revision of every article
+	Author string
+	// Location reads timestamps without an offset, nil reads them in UTC
+	Location *time.Location
+}
+
+// ImportError reports why a record was not imported, Record counts the
+// records of the stream from 1.
+type ImportError struct {
+	Record int    `json:"record" xml:"record" yaml:"record"`
+	Id     string `json:"id,omitempty" xml:"id,omitempty" yaml:"id,omitempty"`
+	Error  string `json:"error" xml:"error" yaml:"error"`
+}
+
+// ImportResponse reports the outcome of a bulk import
+type ImportResponse struct {
+	XMLName  xml.Name      `json:"-" xml:"import" yaml:"-"`
+	Success  bool          `json:"success" xml:"success" yaml:"success"`
+	Imported int           `json:"imported" xml:"imported" yaml:"imported"`
+	Failed   int           `json:"failed" xml:"failed" yaml:"failed"`
+	Aborted  bool          `json:"aborted,omitempty" xml:"aborted,omitempty" yaml:"aborted,omitempty"`
+	Errors   []ImportError `json:"errors,omitempty" xml:"errors>error,omitempty" yaml:"errors,omitempty"`
+}
+
+// importRecord is an article read from an import stream
+type importRecord struct {
+	number  int
+	article Article
+	err     error
+}
+
+// importReader reads the records of an import stream one at a time, it returns
+// io.EOF once the stream is done. A record that can not be read is returned
+// with its err set, any other error ends the import.
+type importReader interface {
+	next() (*importRecord, error)
+}
+
+// importFormat returns the import format of the request body from its
+// Content-Type, JSON lines when there is none.
+func importFormat(contentType string) (string, error) {
+	if contentType == "" {
+		return ImportFormatJSONL, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("bad Content-Type %s provided", contentType)
+	}
+
+	format, ok := importFormats[mediaType]
+	if !ok {
+		return "", fmt.Errorf("unsupported Content-Type %s, expected %s or %s", mediaType, ContentTypeJSONL, ContentTypeCSV)
+	}
+	return format, nil
+}
+
+// ParseOnError reads an on_error value into ImportOptions.SkipInvalid, an
+// empty value aborts.
+func ParseOnError(value string) (bool, error) {
+	switch value {
+	case "", OnErrorAbort:
+		return false, nil
+	case OnErrorSkip:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown on_error %s provided, expected %s or %s", value, OnErrorAbort, OnErrorSkip)
+	}
+}
+
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	switch format {
+	case ImportFormatJSONL:
+		return &jsonlImportReader{reader: bufio.NewReader(r)}, nil
+	case ImportFormatCSV:
+		return newCSVImportReader(r)
+	default:
+		return nil, fmt.Errorf("unknown import format %s", format)
+	}
+}
+
+// jsonlImportReader reads one article per line, blank lines are passed over
+type jsonlImportReader struct {
+	reader *bufio.Reader
+	count  int
+}
+
+func (jr *jsonlImportReader) next() (*importRecord, error) {
+	for {
+		line, err := jr.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		jr.count++
+		record := &importRecord{number: jr.count}
+		if decodeErr := json.Unmarshal(line, &record.article); decodeErr != nil {
+			record.err = fmt.Errorf("bad json provided: %v", decodeErr)
+		}
+		return record, nil
+	}
+}
+
+// csvImportReader reads one article per row after a header row naming the
+// columns, tags and author ids are separated by csvListSeparator.
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns []string
+	count   int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("no csv header provided")
+	} else if err != nil {
+		return nil, err
+	}
+
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !containsFold(articleImportCSVHeader, header[i]) {
+			return nil, fmt.Errorf("unknown csv column %s, expected any of %s",
+				column, strings.Join(articleImportCSVHeader, ", "))
+		}
+	}
+
+	return &csvImportReader{reader: reader, columns: header}, nil
+}
+
+func (cr *csvImportReader) next() (*importRecord, error) {
+	fields, err := cr.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cr.count++
+	record := &importRecord{number: cr.count}
+	if len(fields) != len(cr.columns) {
+		record.err = fmt.Errorf("expected %d csv fields, got %d", len(cr.columns), len(fields))
+		return record, nil
+	}
+
+	for i, column := range cr.columns {
+		value := fields[i]
+		switch column {
+		case "id":
+			record.article.Id = value
+		case "title":
+			record.article.Title = value
+		case "date":
+			record.article.Date = value
+		case "body":
+			record.article.Body = value
+		case "tags":
+			record.article.Tags = splitCSVList(value)
+		case "status":
+			record.article.Status = value
+		case "publish_at":
+			record.article.PublishAt = value
+		case "author_ids":
+			record.article.AuthorIds = splitCSVList(value)
+		}
+	}
+	return record, nil
+}
+
+func splitCSVList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, csvListSeparator) {
+		list = append(list, strings.TrimSpace(item))
+	}
+	return list
+}
+
+// errImportWrite marks the errors of an import the database is at fault for
+var errImportWrite = errors.New("import could not be written")
+
+// articleWriter writes the batches of an import, either the repo itself or
+// the transaction of an import that aborts on the first bad record
+type articleWriter interface {
+	CreateArticles(articles []model.ArticleImport, author string) error
+}
+
+// importer validates the records of an import and writes them in batches
+type importer struct {
+	app       *App
+	options   ImportOptions
+	principal *auth.Principal
+	response  *ImportResponse
+	writer    articleWriter
+	seen      map[int]bool
+	authors   map[string]bool
+	batch     []model.ArticleImport
+	numbers   []int
+	// written holds the articles written in the transaction, they are only
+	// counted and indexed once it commits
+	written []model.ArticleImport
+}
+
+// ImportArticles streams articles in JSON lines or csv from r into the repo,
+// in batches of several rows per statement. Every record is validated as a
+// created article would be and the records that fail are reported. Unless
+// options.SkipInvalid is set every batch is written in one transaction and
+// the first bad record stops the import, rolling back whatever was written.
+func (app *App) ImportArticles(r io.Reader, options ImportOptions) (*ImportResponse, error) {
+	return app.importArticles(r, options, nil)
+}
+
+// importArticles imports on behalf of the principal, who must be allowed to
+// create every article.
+func (app *App) importArticles(r io.Reader, options ImportOptions, principal *auth.Principal) (*ImportResponse, error) {
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultImportBatchSize
+	}
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
+
+	reader, err := newImportReader(r, options.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	im := &importer{
+		app:       app,
+		options:   options,
+		principal: principal,
+		response:  &ImportResponse{},
+		writer:    app.repo,
+		seen:      make(map[int]bool),
+		authors:   make(map[string]bool),
+	}
+
+	var tx repo.ImportTx
+	if !options.SkipInvalid {
+		tx, err = app.repo.BeginImport()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errImportWrite, err)
+		}
+		defer tx.Rollback()
+		im.writer = tx
+	}
+
+	for !im.response.Aborted {
+		record, err := reader.next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		item, err := im.check(record)
+		if err != nil {
+			im.fail(record.number, record.article.Id, err)
+			continue
+		}
+
+		im.batch = append(im.batch, item)
+		im.numbers = append(im.numbers, record.number)
+		if len(im.batch) >= options.BatchSize {
+			im.flush()
+		}
+	}
+
+	if !im.response.Aborted {
+		im.flush()
+	}
+
+	if tx != nil && !im.response.Aborted {
+		if err := tx.Commit(); err != nil {
+			app.logger.Errorf("error committing import because: %v", err)
+			return nil, fmt.Errorf("%w: %v", errImportWrite, err)
+		}
+		im.indexWritten()
+	}
+
+	im.response.Success = im.response.Failed == 0
+	return im.response, nil
+}
+
+// check validates a record and converts it into the article to create
+func (im *importer) check(record *importRecord) (model.ArticleImport, error) {
+	if record.err != nil {
+		return model.ArticleImport{}, record.err
+	}
+
+	article := record.article
+	if err := validateArticle(&article); err != nil {
+		return model.ArticleImport{}, err
+	}
+
+	id, err := strconv.Atoi(article.Id)
+	if err != nil || id <= 0 {
+		return model.ArticleImport{}, errors.New("provided id is not a positive number")
+	}
+	if im.seen[id] {
+		return model.ArticleImport{}, fmt.Errorf("duplicate id %d provided", id)
+	}
+
+	articleModel, err := toArticleModel(&article, im.options.Location)
+	if err != nil {
+		return model.ArticleImport{}, err
+	}
+
+	authorIDs, err := im.checkAuthorIDs(article.AuthorIds)
+	if err != nil {
+		return model.ArticleImport{}, err
+	}
+
+	authorIDs = principalAuthorIDs(im.principal, authorIDs)
+	decision := policy.Authorize(im.principal, policy.ActionCreate,
+		policy.Resource{Kind: policy.KindArticle, OwnerIds: authorIDs})
+	if !decision.Allowed {
+		return model.ArticleImport{}, errors.New(decision.Reason)
+	}
+
+	im.seen[id] = true
+	return model.ArticleImport{Article: articleModel, Tags: article.Tags, AuthorIds: authorIDs}, nil
+}
+
+// checkAuthorIDs converts the author ids of a record and makes sure every
+// author exists, remembering the authors already looked up.
+func (im *importer) checkAuthorIDs(ids []string) ([]int, error) {
+	var authorIDs []int
+	for _, id := range ids {
+		authorID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.New("provided author id is not a number")
+		}
+
+		if !im.authors[id] {
+			_, err = im.app.repo.GetAuthorByID(id)
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("author %s not found", id)
+			} else if err != nil {
+				return nil, err
+			}
+			im.authors[id] = true
+		}
+
+		authorIDs = append(authorIDs, authorID)
+	}
+
+	return authorIDs, nil
+}
+
+// fail reports a record that was not imported
+func (im *importer) fail(number int, id string, err error) {
+	im.response.Failed++
+	im.response.Errors = append(im.response.Errors, ImportError{Record: number, Id: id, Error: err.Error()})
+	if !im.options.SkipInvalid {
+		im.response.Aborted = true
+	}
+}
+
+// flush writes the pending batch. When skipping bad records a failed batch is
+// written again one article at a time, to find the articles at fault.
+func (im *importer) flush() {
+	batch, numbers := im.batch, im.numbers
+	im.batch, im.numbers = nil, nil
+	if len(batch) == 0 {
+		return
+	}
+
+	err := im.writer.CreateArticles(batch, im.options.Author)
+	if err == nil {
+		im.created(batch)
+		return
+	}
+
+	if !im.options.SkipInvalid || len(batch) == 1 {
+		im.app.logger.Errorf("error importing records %d to %d because: %v", numbers[0], numbers[len(numbers)-1], err)
+		im.fail(numbers[0], strconv.Itoa(batch[0].Article.Id),
+			fmt.Errorf("batch of records %d to %d failed: %v", numbers[0], numbers[len(numbers)-1], err))
+		im.response.Failed += len(batch) - 1
+		return
+	}
+
+	for i := range batch {
+		item := batch[i : i+1]
+		if err := im.app.repo.CreateArticles(item, im.options.Author); err != nil {
+			im.fail(numbers[i], strconv.Itoa(item[0].Article.Id), err)
+			continue
+		}
+		im.created(item)
+	}
+}
+
+// created counts the articles of a written batch and indexes them, batches
+// written in a transaction wait for it to commit
+func (im *importer) created(batch []model.ArticleImport) {
+	if !im.options.SkipInvalid {
+		im.written = append(im.written, batch...)
+		return
+	}
+
+	im.response.Imported += len(batch)
+	for _, item := range batch {
+		im.app.syncIndex(item.Article, item.Tags)
+	}
+}
+
+// indexWritten counts and indexes the articles of a committed transaction
+func (im *importer) indexWritten() {
+	im.response.Imported += len(im.written)
+	for _, item := range im.written {
+		im.app.syncIndex(item.Article, item.Tags)
+	}
+	im.written = nil
+}
+
+// postArticlesBatchFunction imports a stream of articles in JSON lines or csv,
+// the on_error parameter picks whether a bad record aborts the import or is
+// skipped.
+func (app *App) postArticlesBatchFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, ImportResponse{}); !ok {
+		return
+	}
+
+	format, err := importFormat(r.Header.Get(HeaderContentType))
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusUnsupportedMediaType)
+		if err != nil {
+			app.logger.Errorf("error checking batch request because: %v", err)
+		}
+		return
+	}
+
+	skipInvalid, err := ParseOnError(r.URL.Query().Get("on_error"))
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error checking batch request because: %v", err)
+		}
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := app.importArticles(r.Body, ImportOptions{
+		Format:      format,
+		SkipInvalid: skipInvalid,
+		Author:      requestAuthor(r),
+		Location:    loc,
+	}, auth.PrincipalFrom(r.Context()))
+	if errors.Is(err, errImportWrite) {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error reading batch request because: %v", err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if response.Aborted {
+		status = http.StatusUnprocessableEntity
+	}
+
+	app.writeResponse(w, r, status, response)
+}
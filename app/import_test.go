@@ -0,0 +1,222 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/auth"
+	"rest-article/search"
+	"strings"
+	"testing"
+)
+
+const importJSONL = `{"id": "10", "title": "first", "date": "2020-02-01", "body": "first body", "tags": ["test"]}
+
+{"id": "11", "title": "second", "date": "2020-02-02", "body": "second body", "tags": ["test", "science"], "author_ids": ["1"]}
+{"id": "12", "title": "third", "date": "2020-02-03", "body": "third body", "tags": ["test"]}
+`
+
+func postBatch(app *App, query, contentType, body string) (*httptest.ResponseRecorder, ImportResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/articles:batch"+query, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(HeaderContentType, contentType)
+	}
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ImportResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+	return resp, respBody
+}
+
+func TestImportJSONL(t *testing.T) {
+	app := newTestApp("TestImportJSONL")
+
+	resp, respBody := postBatch(app, "", ContentTypeJSONL, importJSONL)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, respBody.Success)
+	assert.Equal(t, 3, respBody.Imported)
+	assert.Equal(t, 0, respBody.Failed)
+
+	results, _ := app.index.Search(search.Query{Text: "second"})
+	assert.Len(t, results, 1)
+}
+
+func TestImportCSV(t *testing.T) {
+	app := newTestApp("TestImportCSV")
+
+	body := "id,title,date,body,tags,author_ids\n" +
+		"10,first,2020-02-01,first body,test;science,1;2\n" +
+		"11,second,2020-02-02,\"second, longer body\",test,\n"
+	resp, respBody := postBatch(app, "", ContentTypeCSV+"; charset=utf-8", body)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, respBody.Success)
+	assert.Equal(t, 2, respBody.Imported)
+}
+
+func TestImportRepeatedTags(t *testing.T) {
+	app := newTestApp("TestImportRepeatedTags")
+
+	body := `{"id": "10", "title": "first", "date": "2020-02-01", "body": "first body", "tags": ["go", "go", " go ", ""]}`
+	resp, respBody := postBatch(app, "", ContentTypeJSONL, body)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, respBody.Success)
+	assert.Equal(t, 1, respBody.Imported)
+
+	results, _ := app.index.Search(search.Query{Text: "first", Tag: "go"})
+	assert.Len(t, results, 1)
+
+	// a record of blank tags has none
+	body = `{"id": "11", "title": "second", "date": "2020-02-01", "body": "second body", "tags": [" ", ""]}`
+	_, respBody = postBatch(app, "", ContentTypeJSONL, body)
+
+	assert.Equal(t, 0, respBody.Imported)
+	assert.Equal(t, "no tags provided", respBody.Errors[0].Error)
+}
+
+func TestImportCSVUnknownColumn(t *testing.T) {
+	app := newTestApp("TestImportCSVUnknownColumn")
+
+	resp, _ := postBatch(app, "", ContentTypeCSV, "id,title,colour\n10,first,red\n")
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestImportAbortsOnBadRecord(t *testing.T) {
+	app := newTestApp("TestImportAbortsOnBadRecord")
+
+	body := strings.Replace(importJSONL, `"title": "second", `, "", 1)
+	resp, respBody := postBatch(app, "", ContentTypeJSONL, body)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.False(t, respBody.Success)
+	assert.True(t, respBody.Aborted)
+	assert.Equal(t, 0, respBody.Imported)
+	assert.Equal(t, []ImportError{{Record: 2, Id: "11", Error: "no title provided"}}, respBody.Errors)
+}
+
+func TestImportSkipsBadRecords(t *testing.T) {
+	app := newTestApp("TestImportSkipsBadRecords")
+
+	body := importJSONL +
+		"{not json}\n" +
+		`{"id": "12", "title": "again", "date": "2020-02-03", "body": "again", "tags": ["test"]}` + "\n" +
+		`{"id": "13", "title": "later", "date": "2020-02-04", "body": "later", "tags": ["test"], "author_ids": ["nine"]}` + "\n" +
+		`{"id": "14", "title": "scheduled", "date": "2020-02-05", "body": "soon", "tags": ["test"], "status": "scheduled"}` + "\n"
+	resp, respBody := postBatch(app, "?on_error=skip", ContentTypeJSONL, body)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.False(t, respBody.Success)
+	assert.False(t, respBody.Aborted)
+	assert.Equal(t, 3, respBody.Imported)
+	assert.Equal(t, 4, respBody.Failed)
+
+	var records []int
+	for _, importError := range respBody.Errors {
+		records = append(records, importError.Record)
+	}
+	assert.Equal(t, []int{4, 5, 6, 7}, records)
+	assert.Equal(t, "duplicate id 12 provided", respBody.Errors[1].Error)
+	assert.Equal(t, "provided author id is not a number", respBody.Errors[2].Error)
+}
+
+func TestImportSkipsFailedBatchRecords(t *testing.T) {
+	app := newTestApp("TestImportSkipsFailedBatchRecords")
+
+	// article 2 exists already, so the batch fails and is written again one
+	// article at a time
+	body := strings.Replace(importJSONL, `"id": "11"`, `"id": "2"`, 1)
+	resp, respBody := postBatch(app, "?on_error=skip", ContentTypeJSONL, body)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, respBody.Imported)
+	assert.Equal(t, 1, respBody.Failed)
+	assert.Equal(t, 2, respBody.Errors[0].Record)
+	assert.Equal(t, "2", respBody.Errors[0].Id)
+}
+
+func TestImportAbortsFailedBatch(t *testing.T) {
+	app := newTestApp("TestImportAbortsFailedBatch")
+
+	body := strings.Replace(importJSONL, `"id": "11"`, `"id": "2"`, 1)
+	resp, respBody := postBatch(app, "", ContentTypeJSONL, body)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.True(t, respBody.Aborted)
+	assert.Equal(t, 0, respBody.Imported)
+	assert.Equal(t, 3, respBody.Failed)
+	assert.Contains(t, respBody.Errors[0].Error, "batch of records 1 to 3 failed")
+}
+
+func TestImportBatchSize(t *testing.T) {
+	app := newTestApp("TestImportBatchSize")
+
+	// the failing article sits in the second batch, the first is rolled back
+	// along with it
+	body := strings.Replace(importJSONL, `"id": "12"`, `"id": "3"`, 1)
+	respBody, err := app.ImportArticles(strings.NewReader(body), ImportOptions{Format: ImportFormatJSONL, BatchSize: 2})
+
+	assert.Nil(t, err)
+	assert.True(t, respBody.Aborted)
+	assert.Equal(t, 0, respBody.Imported)
+	assert.Equal(t, 3, respBody.Errors[0].Record)
+
+	results, _ := app.index.Search(search.Query{Text: "first"})
+	assert.Empty(t, results)
+}
+
+func TestImportWriteFailure(t *testing.T) {
+	app := newTestApp("TestImportWriteFailure")
+	app.repo = NewMockArticleRepo(errors.New("database is down"))
+
+	resp, _ := postBatch(app, "", ContentTypeJSONL, importJSONL)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestImportBadRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		status      int
+	}{
+		{"unsupported content type", "", "application/xml", http.StatusUnsupportedMediaType},
+		{"unknown on_error", "?on_error=retry", ContentTypeJSONL, http.StatusBadRequest},
+		{"unknown timezone", "?tz=Mars/Olympus", ContentTypeJSONL, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestImportBadRequests")
+
+			resp, _ := postBatch(app, test.query, test.contentType, importJSONL)
+
+			assert.Equal(t, test.status, resp.Code)
+		})
+	}
+}
+
+func TestImportPolicy(t *testing.T) {
+	app := newTestApp("TestImportPolicy", withStaticKeys(policyKeys...))
+
+	body := strings.Replace(importJSONL, `"author_ids": ["1"]`, `"author_ids": ["2"]`, 1)
+	req := httptest.NewRequest(http.MethodPost, "/articles:batch?on_error=skip", strings.NewReader(body))
+	req.Header.Set(auth.HeaderAPIKey, "owner-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ImportResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, respBody.Imported)
+	assert.Equal(t, []ImportError{{Record: 2, Id: "11", Error: "authors can only create their own article"}}, respBody.Errors)
+}
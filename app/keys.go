@@ -0,0 +1,236 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"rest-article/auth"
+	"rest-article/database/model"
+)
+
+type APIKey struct {
+	Id        string   `json:"id" xml:"id" yaml:"id"`
+	Name      string   `json:"name" xml:"name" yaml:"name"`
+	Role      string   `json:"role" xml:"role" yaml:"role"`
+	AuthorId  string   `json:"author_id,omitempty" xml:"author_id,omitempty" yaml:"author_id,omitempty"`
+	Scopes    []string `json:"scopes" xml:"scopes>scope" yaml:"scopes"`
+	CreatedAt string   `json:"created_at" xml:"created_at" yaml:"created_at"`
+	RevokedAt string   `json:"revoked_at,omitempty" xml:"revoked_at,omitempty" yaml:"revoked_at,omitempty"`
+}
+
+type APIKeysResponse struct {
+	XMLName xml.Name `json:"-" xml:"keys" yaml:"-"`
+	Count   int      `json:"count" xml:"count" yaml:"count"`
+	Keys    []APIKey `json:"keys" xml:"key" yaml:"keys"`
+}
+
+type PostAPIKeyRequest struct {
+	Name     string   `json:"name"`
+	Role     string   `json:"role"`
+	AuthorId string   `json:"author_id"`
+	Scopes   []string `json:"scopes"`
+}
+
+// CreateAPIKeyResponse carries the only copy of the plain text key, the
+// service keeps nothing but its hash.
+type CreateAPIKeyResponse struct {
+	XMLName xml.Name `json:"-" xml:"created" yaml:"-"`
+	Success bool     `json:"success" xml:"success" yaml:"success"`
+	Id      int      `json:"id" xml:"id" yaml:"id"`
+	Key     string   `json:"key" xml:"key" yaml:"key"`
+}
+
+var knownScopes = map[string]bool{
+	auth.ScopeArticlesRead:  true,
+	auth.ScopeArticlesWrite: true,
+	auth.ScopeArticlesAdmin: true,
+	auth.ScopeTagsAdmin:     true,
+	auth.ScopeKeysAdmin:     true,
+}
+
+var knownRoles = map[string]bool{
+	auth.RoleReader: true,
+	auth.RoleAuthor: true,
+	auth.RoleEditor: true,
+	auth.RoleAdmin:  true,
+}
+
+func (app *App) getAPIKeysFunction(w http.ResponseWriter, r *http.Request) {
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := app.repo.GetAPIKeys()
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := APIKeysResponse{
+		Count: len(keys),
+		Keys:  []APIKey{},
+	}
+	for _, key := range keys {
+		apiKey := APIKey{
+			Id:        fmt.Sprintf("%d", key.Id),
+			Name:      key.Name,
+			Role:      key.Role,
+			Scopes:    key.Scopes,
+			CreatedAt: formatTimestamp(key.CreatedAt, loc),
+		}
+		if key.AuthorId != 0 {
+			apiKey.AuthorId = fmt.Sprintf("%d", key.AuthorId)
+		}
+		if key.RevokedAt != nil {
+			apiKey.RevokedAt = formatTimestamp(*key.RevokedAt, loc)
+		}
+		response.Keys = append(response.Keys, apiKey)
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) postAPIKeyFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, CreateAPIKeyResponse{}); !ok {
+		return
+	}
+
+	var request PostAPIKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error decoding json post body because: %v", err)
+		}
+		return
+	}
+
+	if request.Name == "" {
+		err = handleError(w, "no name provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no name provided")
+		}
+		return
+	}
+
+	if len(request.Scopes) == 0 {
+		err = handleError(w, "no scopes provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no scopes provided")
+		}
+		return
+	}
+
+	for _, scope := range request.Scopes {
+		if !knownScopes[scope] {
+			err = handleError(w, fmt.Sprintf("unknown scope %s provided", scope), http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("unknown scope %s provided", scope)
+			}
+			return
+		}
+	}
+
+	if request.Role == "" {
+		request.Role = auth.RoleReader
+	}
+
+	if !knownRoles[request.Role] {
+		err = handleError(w, fmt.Sprintf("unknown role %s provided", request.Role), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("unknown role %s provided", request.Role)
+		}
+		return
+	}
+
+	var authorIDs []int
+	if request.AuthorId != "" {
+		authorIDs, err = app.checkAuthorIDs([]string{request.AuthorId})
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("error checking post request because: %v", err)
+			}
+			return
+		}
+	} else if request.Role == auth.RoleAuthor {
+		err = handleError(w, "no author_id provided for author role", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no author_id provided for author role")
+		}
+		return
+	}
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	apiKey := model.APIKey{
+		Name:   request.Name,
+		Hash:   hash,
+		Role:   request.Role,
+		Scopes: request.Scopes,
+	}
+	if len(authorIDs) > 0 {
+		apiKey.AuthorId = authorIDs[0]
+	}
+
+	keyRes, err := app.repo.CreateAPIKey(apiKey)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := CreateAPIKeyResponse{
+		Success: true,
+		Id:      keyRes.Id,
+		Key:     key,
+	}
+
+	app.writeResponse(w, r, http.StatusCreated, response)
+}
+
+func (app *App) deleteAPIKeyFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, ActionResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := app.repo.RevokeAPIKey(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "api key not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("api key not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	app.writeActionResponse(w, r, id)
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"rest-article/auth"
+)
+
+// HeaderWWWAuthenticate is sent with every 401 response
+const HeaderWWWAuthenticate = "WWW-Authenticate"
+
+// identify stores the principal of requests with valid credentials on the
+// request context, before rate limits are applied. Requests without are let
+// through for authorize to turn away.
+func (app *App) identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.authenticator == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := app.authenticator.Authenticate(r)
+		if err == nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize only lets requests through whose credentials carry the scope, the
+// principal is stored on the request context for the handler.
+func (app *App) authorize(scope string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.authenticator == nil {
+			handler(w, r)
+			return
+		}
+
+		// identify has authenticated the request already when it went through
+		// the router
+		principal := auth.PrincipalFrom(r.Context())
+		var err error
+		if principal == nil {
+			principal, err = app.authenticator.Authenticate(r)
+		}
+		if errors.Is(err, auth.ErrNoCredentials) || errors.Is(err, auth.ErrInvalidCredentials) {
+			w.Header().Set(HeaderWWWAuthenticate, `Bearer realm="rest-article"`)
+			err = handleError(w, err.Error(), http.StatusUnauthorized)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		} else if err != nil {
+			app.logger.Errorf("error authenticating request because: %v", err)
+			err = handleError(w, "unable to authenticate request", http.StatusInternalServerError)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			err = handleError(w, fmt.Sprintf("missing scope %s", scope), http.StatusForbidden)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		handler(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/auth"
+	"rest-article/repo"
+	"strings"
+	"testing"
+)
+
+// adminKey may manage keys and nothing else
+var adminKey = auth.StaticKey{Name: "admin", Hash: auth.HashKey("admin-key"), Scopes: []string{auth.ScopeKeysAdmin}}
+
+func TestAuthorizeNoCredentials(t *testing.T) {
+	app := newTestApp("TestAuthorizeNoCredentials", withStaticKeys(adminKey))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(HeaderWWWAuthenticate))
+}
+
+func TestAuthorizeMissingScope(t *testing.T) {
+	app := newTestApp("TestAuthorizeMissingScope", withStaticKeys(adminKey))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+	req.Header.Set(auth.HeaderAPIKey, repo.MockAPIKey)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, ResponseError("missing scope keys:admin"), respBody.Error)
+}
+
+func TestAuthorizeStoredKey(t *testing.T) {
+	app := newTestApp("TestAuthorizeStoredKey", withStaticKeys(adminKey))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set(auth.HeaderAuthorization, "Bearer "+repo.MockAPIKey)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestPostAPIKeyFunction(t *testing.T) {
+	app := newTestApp("TestPostAPIKeyFunction", withStaticKeys(adminKey))
+
+	body := strings.NewReader(`{"name": "reader", "scopes": ["articles:read"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/admin/keys", body)
+	req.Header.Set(auth.HeaderAPIKey, "admin-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody CreateAPIKeyResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.True(t, strings.HasPrefix(respBody.Key, "ra_"))
+}
+
+func TestPostAPIKeyFunctionUnknownScope(t *testing.T) {
+	app := newTestApp("TestPostAPIKeyFunctionUnknownScope", withStaticKeys(adminKey))
+
+	body := strings.NewReader(`{"name": "reader", "scopes": ["everything"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/admin/keys", body)
+	req.Header.Set(auth.HeaderAPIKey, "admin-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, ResponseError("unknown scope everything provided"), respBody.Error)
+}
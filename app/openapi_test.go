@@ -0,0 +1,177 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Servers    []openAPIServer                       `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPIDocument(t *testing.T) openAPIDocument {
+	var document openAPIDocument
+	if err := json.Unmarshal([]byte(openAPISpec), &document); err != nil {
+		t.Fatalf("openapi document is not valid json: %v", err)
+	}
+	return document
+}
+
+func TestOpenAPIFunction(t *testing.T) {
+	app := newTestApp("TestOpenAPIFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	var document openAPIDocument
+	err := json.NewDecoder(resp.Body).Decode(&document)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, err)
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+}
+
+// TestOpenAPIMatchesRouter fails when a route is added to SetupRouter without
+// documenting it, or documented without being routed. Documented paths are
+// served under every server of the document, unless they list their own.
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	app := newTestApp("TestOpenAPIMatchesRouter")
+	document := loadOpenAPIDocument(t)
+
+	var routed []string
+	_ = app.Router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			// preflight routes are generated for every path
+			if method != http.MethodOptions {
+				routed = append(routed, method+" "+path)
+			}
+		}
+		return nil
+	})
+
+	var documented []string
+	for path, operations := range document.Paths {
+		servers := document.Servers
+		if raw, ok := operations["servers"]; ok {
+			servers = nil
+			_ = json.Unmarshal(raw, &servers)
+		}
+
+		for method := range operations {
+			if method == "servers" {
+				continue
+			}
+			for _, server := range servers {
+				documented = append(documented, strings.ToUpper(method)+" "+strings.TrimSuffix(server.URL, "/")+path)
+			}
+		}
+	}
+
+	sort.Strings(routed)
+	sort.Strings(documented)
+	assert.Equal(t, routed, documented)
+}
+
+// TestOpenAPISchemasMatchTypes fails when the json fields of a response type and
+// its schema drift apart.
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	document := loadOpenAPIDocument(t)
+
+	types := map[string]interface{}{
+		"Article":                 Article{},
+		"ArticlesResponse":        ArticlesResponse{},
+		"CreateArticleResponse":   CreateArticleResponse{},
+		"TagSummaryResponse":      TagSummaryResponse{},
+		"TagSummaryResponseV1":    TagSummaryResponseV1{},
+		"Revision":                Revision{},
+		"RevisionsResponse":       RevisionsResponse{},
+		"DiffEdit":                DiffEdit{},
+		"DateChange":              DateChange{},
+		"RevisionDiffResponse":    RevisionDiffResponse{},
+		"Author":                  Author{},
+		"AuthorsResponse":         AuthorsResponse{},
+		"CreateAuthorResponse":    CreateAuthorResponse{},
+		"SearchResult":            SearchResult{},
+		"SearchResponse":          SearchResponse{},
+		"DeletedArticle":          DeletedArticle{},
+		"DeletedArticlesResponse": DeletedArticlesResponse{},
+		"ActionResponse":          ActionResponse{},
+		"TagCount":                TagCount{},
+		"TagCountsResponse":       TagCountsResponse{},
+		"MergeTagsRequest":        MergeTagsRequest{},
+		"MergeTagsResponse":       MergeTagsResponse{},
+		"APIKey":                  APIKey{},
+		"APIKeysResponse":         APIKeysResponse{},
+		"PostAPIKeyRequest":       PostAPIKeyRequest{},
+		"CreateAPIKeyResponse":    CreateAPIKeyResponse{},
+		"ErrorResponse":           ErrorResponse{},
+		"ImportError":             ImportError{},
+		"ImportResponse":          ImportResponse{},
+		"DumpRecord":              DumpRecord{},
+		"DumpAuthor":              DumpAuthor{},
+		"DumpArticle":             DumpArticle{},
+		"DumpRevision":            DumpRevision{},
+		"ProblemResponse":         ProblemResponse{},
+	}
+
+	for name, value := range types {
+		schema, ok := document.Components.Schemas[name]
+		if !assert.True(t, ok, "schema %s is missing", name) {
+			continue
+		}
+
+		var documented []string
+		for property := range schema.Properties {
+			documented = append(documented, property)
+		}
+
+		sort.Strings(documented)
+		assert.Equal(t, jsonFields(reflect.TypeOf(value)), documented, "schema %s", name)
+	}
+}
+
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest-article/auth"
+	"rest-article/policy"
+)
+
+// ContentTypeProblemJSON is the content type of RFC 7807 problem bodies
+const ContentTypeProblemJSON = "application/problem+json"
+
+// ProblemResponse is an RFC 7807 problem body
+type ProblemResponse struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// permit consults the policy for the principal of the request, a denial is
+// written to w as a problem and reported as false.
+func (app *App) permit(w http.ResponseWriter, r *http.Request, action string, resource policy.Resource) bool {
+	principal := auth.PrincipalFrom(r.Context())
+
+	decision := policy.Authorize(principal, action, resource)
+	if decision.Allowed {
+		return true
+	}
+
+	app.logger.Infof("denied %s %s to %s because: %s", action, resource.Kind, principal.Subject, decision.Reason)
+	err := handleProblem(w, r, decision.Reason, http.StatusForbidden)
+	if err != nil {
+		app.logger.Errorf("error sending problem response because: %v", err)
+	}
+	return false
+}
+
+// permitArticle consults the policy for an action on an existing article, its
+// current authors are its owners.
+func (app *App) permitArticle(w http.ResponseWriter, r *http.Request, action string, articleID int) bool {
+	if auth.PrincipalFrom(r.Context()) == nil {
+		return true
+	}
+
+	authors, err := app.repo.GetArticleAuthors([]int{articleID})
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return false
+	}
+
+	var owners []int
+	for _, author := range authors[articleID] {
+		owners = append(owners, author.Id)
+	}
+
+	return app.permit(w, r, action, policy.Resource{Kind: policy.KindArticle, OwnerIds: owners})
+}
+
+// ownAuthorIDs attributes articles created by principals with the author role
+// to their own author when the request names none.
+func ownAuthorIDs(r *http.Request, authorIDs []int) []int {
+	return principalAuthorIDs(auth.PrincipalFrom(r.Context()), authorIDs)
+}
+
+// principalAuthorIDs attributes articles created by the principal to its own
+// author when it has the author role and names none.
+func principalAuthorIDs(principal *auth.Principal, authorIDs []int) []int {
+	if len(authorIDs) == 0 && principal != nil && principal.Role == auth.RoleAuthor && principal.AuthorId != 0 {
+		return []int{principal.AuthorId}
+	}
+	return authorIDs
+}
+
+func handleProblem(w http.ResponseWriter, r *http.Request, detail string, statusCode int) error {
+
+	body, err := json.Marshal(ProblemResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	})
+
+	if err != nil {
+		logger.Errorf("error in marshaling JSON problem response because: %v", err)
+		return err
+	}
+
+	w.Header().Add(HeaderContentType, ContentTypeProblemJSON)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		logger.Errorf("error writing response because: %v", err)
+		return err
+	}
+
+	return nil
+}
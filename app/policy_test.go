@@ -0,0 +1,122 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/auth"
+	"strings"
+	"testing"
+)
+
+const articleBody = `{"title": "test article", "date": "2020-02-01", "body": "test body", "tags": ["test"]}`
+
+// policyScopes let every policy key read and write, so the roles decide
+var policyScopes = []string{auth.ScopeArticlesRead, auth.ScopeArticlesWrite}
+
+// policyKeys are a reader, the author of the mock articles, another author and
+// an editor
+var policyKeys = []auth.StaticKey{
+	{Name: "reader", Hash: auth.HashKey("reader-key"), Role: auth.RoleReader, Scopes: policyScopes},
+	{Name: "owner", Hash: auth.HashKey("owner-key"), Role: auth.RoleAuthor, AuthorId: 1, Scopes: policyScopes},
+	{Name: "other", Hash: auth.HashKey("other-key"), Role: auth.RoleAuthor, AuthorId: 2, Scopes: policyScopes},
+	{Name: "editor", Hash: auth.HashKey("editor-key"), Role: auth.RoleEditor, Scopes: policyScopes},
+}
+
+func TestPolicyArticleWrites(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		status int
+	}{
+		{"reader reads", "reader-key", http.MethodGet, "/articles/1", http.StatusOK},
+		{"reader creates", "reader-key", http.MethodPost, "/articles", http.StatusForbidden},
+		{"reader updates", "reader-key", http.MethodPut, "/articles/1", http.StatusForbidden},
+		{"owner creates", "owner-key", http.MethodPost, "/articles", http.StatusCreated},
+		{"owner updates", "owner-key", http.MethodPut, "/articles/1", http.StatusOK},
+		{"owner deletes", "owner-key", http.MethodDelete, "/articles/1", http.StatusOK},
+		{"other author updates", "other-key", http.MethodPut, "/articles/1", http.StatusForbidden},
+		{"other author deletes", "other-key", http.MethodDelete, "/articles/1", http.StatusForbidden},
+		{"editor updates", "editor-key", http.MethodPut, "/articles/1", http.StatusOK},
+		{"editor deletes", "editor-key", http.MethodDelete, "/articles/1", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestPolicyArticleWrites", withStaticKeys(policyKeys...))
+
+			body := `{"id": "1", ` + articleBody[1:]
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(body))
+			req.Header.Set(auth.HeaderAPIKey, test.key)
+			req.Header.Set(HeaderIfMatch, "*")
+			resp := httptest.NewRecorder()
+
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.status, resp.Code)
+		})
+	}
+}
+
+func TestPolicyDeniedProblem(t *testing.T) {
+	app := newTestApp("TestPolicyDeniedProblem", withStaticKeys(policyKeys...))
+
+	req := httptest.NewRequest(http.MethodPut, "/articles/1", strings.NewReader(articleBody))
+	req.Header.Set(auth.HeaderAPIKey, "other-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ProblemResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, ContentTypeProblemJSON, resp.Header().Get(HeaderContentType))
+	assert.Equal(t, http.StatusForbidden, respBody.Status)
+	assert.Equal(t, "/articles/1", respBody.Instance)
+	assert.Equal(t, "authors can only update their own article", respBody.Detail)
+}
+
+func TestPolicyAuthorCannotHandOverArticle(t *testing.T) {
+	app := newTestApp("TestPolicyAuthorCannotHandOverArticle", withStaticKeys(policyKeys...))
+
+	body := `{"author_ids": ["2"], ` + articleBody[1:]
+	req := httptest.NewRequest(http.MethodPut, "/articles/1", strings.NewReader(body))
+	req.Header.Set(auth.HeaderAPIKey, "owner-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestPolicyAuthorProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{"updates own profile", http.MethodPut, "/authors/1", http.StatusOK},
+		{"updates other profile", http.MethodPut, "/authors/2", http.StatusForbidden},
+		{"creates author", http.MethodPost, "/authors", http.StatusForbidden},
+		{"deletes own profile", http.MethodDelete, "/authors/1", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app := newTestApp("TestPolicyAuthorProfile", withStaticKeys(policyKeys...))
+
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(`{"name": "test author"}`))
+			req.Header.Set(auth.HeaderAPIKey, "owner-key")
+			resp := httptest.NewRecorder()
+
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.status, resp.Code)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"rest-article/auth"
+	"rest-article/ratelimit"
+	"strings"
+	"time"
+)
+
+// Rate limit header constants
+const (
+	HeaderRetryAfter              = "Retry-After"
+	HeaderRateLimitLimit          = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining      = "X-RateLimit-Remaining"
+	HeaderRateLimitReset          = "X-RateLimit-Reset"
+	HeaderRateLimitQuotaLimit     = "X-RateLimit-Quota-Limit"
+	HeaderRateLimitQuotaRemaining = "X-RateLimit-Quota-Remaining"
+	HeaderForwardedFor            = "X-Forwarded-For"
+)
+
+// RateLimits are the per client limits of the API, GET, HEAD and OPTIONS
+// requests count against Read and every other request against Write. Nil limits are not
+// enforced.
+type RateLimits struct {
+	Read              *ratelimit.Limiter
+	Write             *ratelimit.Limiter
+	Quota             *ratelimit.Quota
+	TrustForwardedFor bool
+}
+
+// SetRateLimits enforces the limits on every route, nil turns rate limiting off
+func (app *App) SetRateLimits(limits *RateLimits) {
+	app.runtime.update(func(runtime *Runtime) { runtime.RateLimits = limits })
+}
+
+// rateLimit rejects requests of clients that ran out of tokens or used up their
+// daily quota.
+func (app *App) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits := app.runtime.load().RateLimits
+		if limits == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limiter := limits.Write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			limiter = limits.Read
+		}
+
+		key := clientKey(r, limits.TrustForwardedFor)
+
+		if limiter != nil {
+			result := limiter.Allow(key)
+			w.Header().Set(HeaderRateLimitLimit, fmt.Sprintf("%d", result.Limit))
+			w.Header().Set(HeaderRateLimitRemaining, fmt.Sprintf("%d", result.Remaining))
+			w.Header().Set(HeaderRateLimitReset, fmt.Sprintf("%d", seconds(result.Reset)))
+			if !result.Allowed {
+				app.rejectRequest(w, "rate limit exceeded", result.RetryAfter)
+				return
+			}
+		}
+
+		if limits.Quota != nil {
+			result := limits.Quota.Use(key)
+			w.Header().Set(HeaderRateLimitQuotaLimit, fmt.Sprintf("%d", result.Limit))
+			w.Header().Set(HeaderRateLimitQuotaRemaining, fmt.Sprintf("%d", result.Remaining))
+			if !result.Allowed {
+				app.rejectRequest(w, "daily quota exceeded", result.RetryAfter)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *App) rejectRequest(w http.ResponseWriter, message string, retryAfter time.Duration) {
+	w.Header().Set(HeaderRetryAfter, fmt.Sprintf("%d", seconds(retryAfter)))
+	err := handleError(w, message, http.StatusTooManyRequests)
+	if err != nil {
+		app.logger.Errorf("error sending error response because: %v", err)
+	}
+}
+
+// clientKey identifies the client of the request by the principal it
+// authenticated as, falling back to its IP address. Credentials are only
+// trusted once they authenticate, so made up keys share the bucket of the IP
+// they are sent from.
+func clientKey(r *http.Request, trustForwardedFor bool) string {
+	if principal := auth.PrincipalFrom(r.Context()); principal != nil {
+		return "principal:" + principal.Method + ":" + principal.Subject
+	}
+
+	if trustForwardedFor {
+		if forwarded := r.Header.Get(HeaderForwardedFor); forwarded != "" {
+			return "ip:" + strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// seconds rounds d up to whole seconds, as used by the rate limit headers
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/auth"
+	"rest-article/ratelimit"
+	"testing"
+)
+
+func TestRateLimitExceeded(t *testing.T) {
+	app := newTestApp("TestRateLimitExceeded", withRateLimits(&RateLimits{
+		Read: ratelimit.NewLimiter(1, 1),
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", resp.Header().Get(HeaderRateLimitRemaining))
+
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(HeaderRetryAfter))
+	assert.Equal(t, ResponseError("rate limit exceeded"), respBody.Error)
+}
+
+func TestRateLimitSeparateClientsAndRoutes(t *testing.T) {
+	app := newTestApp("TestRateLimitSeparateClientsAndRoutes", withRateLimits(&RateLimits{
+		Read:  ratelimit.NewLimiter(1, 1),
+		Write: ratelimit.NewLimiter(1, 1),
+	}), withStaticKeys(
+		auth.StaticKey{Name: "first", Hash: auth.HashKey("first-key"), Role: auth.RoleEditor, Scopes: []string{auth.ScopeArticlesRead, auth.ScopeArticlesWrite}},
+		auth.StaticKey{Name: "second", Hash: auth.HashKey("second-key"), Role: auth.RoleEditor, Scopes: []string{auth.ScopeArticlesRead, auth.ScopeArticlesWrite}},
+	))
+
+	for _, key := range []string{"first-key", "second-key"} {
+		req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+		req.Header.Set(auth.HeaderAPIKey, key)
+		resp := httptest.NewRecorder()
+		app.Router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/articles/1", nil)
+	req.Header.Set(auth.HeaderAPIKey, "first-key")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestRateLimitDailyQuota(t *testing.T) {
+	app := newTestApp("TestRateLimitDailyQuota", withRateLimits(&RateLimits{
+		Quota: ratelimit.NewQuota(1),
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "0", resp.Header().Get(HeaderRateLimitQuotaRemaining))
+
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(HeaderRetryAfter))
+}
+
+func TestRateLimitInvalidKeysShareIPBucket(t *testing.T) {
+	app := newTestApp("TestRateLimitInvalidKeysShareIPBucket", withRateLimits(&RateLimits{
+		Read: ratelimit.NewLimiter(1, 1),
+	}), withStaticKeys())
+
+	var codes []int
+	for _, key := range []string{"made-up-1", "made-up-2"} {
+		req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+		req.Header.Set(auth.HeaderAPIKey, key)
+		resp := httptest.NewRecorder()
+		app.Router.ServeHTTP(resp, req)
+		codes = append(codes, resp.Code)
+	}
+
+	// the first is turned away for its key, the second for the bucket of its IP
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set(HeaderForwardedFor, "192.168.0.1, 10.0.0.1")
+
+	assert.Equal(t, "ip:10.0.0.1", clientKey(req, false))
+	assert.Equal(t, "ip:192.168.0.1", clientKey(req, true))
+
+	// credentials that did not authenticate are not trusted
+	req.Header.Set(auth.HeaderAPIKey, "secret")
+	assert.Equal(t, "ip:10.0.0.1", clientKey(req, false))
+
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "editor", Method: auth.MethodAPIKey}))
+	assert.Equal(t, "principal:api_key:editor", clientKey(req, false))
+}
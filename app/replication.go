@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"rest-article/repo"
+	"sync"
+	"time"
+)
+
+const primaryKey = contextKey("primary")
+
+// ReadYourWrites pins the reads of a client to the primary database for Window
+// after it sent a write, so it reads back what it wrote while the replicas
+// catch up. Clients are told apart as for rate limits.
+type ReadYourWrites struct {
+	Window            time.Duration
+	TrustForwardedFor bool
+
+	mu     sync.Mutex
+	writes map[string]time.Time
+	pruned time.Time
+}
+
+// NewReadYourWrites returns the policy pinning a client for window after a write
+func NewReadYourWrites(window time.Duration, trustForwardedFor bool) *ReadYourWrites {
+	return &ReadYourWrites{
+		Window:            window,
+		TrustForwardedFor: trustForwardedFor,
+		writes:            make(map[string]time.Time),
+	}
+}
+
+// wrote records a write of the client at now, clients whose window passed are
+// forgotten at most once a window
+func (rw *ReadYourWrites) wrote(key string, now time.Time) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.writes[key] = now
+
+	if now.Sub(rw.pruned) < rw.Window {
+		return
+	}
+	for client, at := range rw.writes {
+		if now.Sub(at) >= rw.Window {
+			delete(rw.writes, client)
+		}
+	}
+	rw.pruned = now
+}
+
+// pinned reports whether the client wrote within the window before now
+func (rw *ReadYourWrites) pinned(key string, now time.Time) bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	at, ok := rw.writes[key]
+	return ok && now.Sub(at) < rw.Window
+}
+
+// SetReadYourWrites pins clients to the primary after their writes, nil lets
+// every read go to the replicas
+func (app *App) SetReadYourWrites(rw *ReadYourWrites) {
+	app.readYourWrites = rw
+}
+
+// pinReads serves writes, and the reads of clients that wrote within the
+// window, from the primary database
+func (app *App) pinReads(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := app.readYourWrites
+		if rw == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := clientKey(r, rw.TrustForwardedFor)
+		now := time.Now()
+
+		pinned := true
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			pinned = rw.pinned(key, now)
+		} else {
+			rw.wrote(key, now)
+		}
+
+		if pinned {
+			r = r.WithContext(context.WithValue(r.Context(), primaryKey, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reader returns the repo the reads of the request go to, the primary for
+// requests pinned to it
+func (app *App) reader(r *http.Request) repo.Repo {
+	if pinned, _ := r.Context().Value(primaryKey).(bool); pinned {
+		return app.repo.Primary()
+	}
+	return app.repo
+}
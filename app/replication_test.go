@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPinReads(t *testing.T) {
+	app := &App{}
+	app.SetReadYourWrites(NewReadYourWrites(time.Minute, false))
+
+	var pinned bool
+	handler := app.pinReads(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinned, _ = r.Context().Value(primaryKey).(bool)
+	}))
+
+	serve := func(method, addr string) bool {
+		req := httptest.NewRequest(method, "/articles/1", nil)
+		req.RemoteAddr = addr
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return pinned
+	}
+
+	assert.False(t, serve(http.MethodGet, "10.0.0.1:5000"))
+	assert.True(t, serve(http.MethodPut, "10.0.0.1:5000"))
+	assert.True(t, serve(http.MethodGet, "10.0.0.1:5000"))
+	assert.False(t, serve(http.MethodGet, "10.0.0.2:5000"))
+}
+
+func TestReadYourWritesWindow(t *testing.T) {
+	rw := NewReadYourWrites(time.Second, false)
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	rw.wrote("key:a", now)
+	assert.True(t, rw.pinned("key:a", now.Add(999*time.Millisecond)))
+	assert.False(t, rw.pinned("key:a", now.Add(time.Second)))
+
+	// a later write forgets the clients whose window passed
+	rw.wrote("key:b", now.Add(2*time.Second))
+	assert.Len(t, rw.writes, 1)
+}
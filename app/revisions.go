@@ -0,0 +1,322 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"rest-article/database/model"
+	"rest-article/diff"
+	"rest-article/policy"
+	"strconv"
+	"time"
+)
+
+type Revision struct {
+	XMLName   xml.Name `json:"-" xml:"revision" yaml:"-"`
+	Revision  int      `json:"revision" xml:"revision" yaml:"revision"`
+	ArticleId string   `json:"article_id" xml:"article_id" yaml:"article_id"`
+	Title     string   `json:"title" xml:"title" yaml:"title"`
+	Date      string   `json:"date" xml:"date" yaml:"date"`
+	Body      string   `json:"body" xml:"body" yaml:"body"`
+	Tags      []string `json:"tags" xml:"tags>tag" yaml:"tags"`
+	Author    string   `json:"author" xml:"author" yaml:"author"`
+	CreatedAt string   `json:"created_at" xml:"created_at" yaml:"created_at"`
+}
+
+type RevisionsResponse struct {
+	XMLName   xml.Name   `json:"-" xml:"revisions" yaml:"-"`
+	ArticleId string     `json:"article_id" xml:"article_id" yaml:"article_id"`
+	Count     int        `json:"count" xml:"count" yaml:"count"`
+	Revisions []Revision `json:"revisions" xml:"revision" yaml:"revisions"`
+}
+
+type DiffEdit struct {
+	Op   string `json:"op" xml:"op" yaml:"op"`
+	Text string `json:"text" xml:"text" yaml:"text"`
+}
+
+type DateChange struct {
+	From string `json:"from" xml:"from" yaml:"from"`
+	To   string `json:"to" xml:"to" yaml:"to"`
+}
+
+type RevisionDiffResponse struct {
+	XMLName     xml.Name    `json:"-" xml:"revision_diff" yaml:"-"`
+	ArticleId   string      `json:"article_id" xml:"article_id" yaml:"article_id"`
+	From        int         `json:"from" xml:"from" yaml:"from"`
+	To          int         `json:"to" xml:"to" yaml:"to"`
+	Title       []DiffEdit  `json:"title" xml:"title>edit" yaml:"title"`
+	Body        []DiffEdit  `json:"body" xml:"body>edit" yaml:"body"`
+	Date        *DateChange `json:"date,omitempty" xml:"date,omitempty" yaml:"date,omitempty"`
+	TagsAdded   []string    `json:"tags_added" xml:"tags_added>tag" yaml:"tags_added"`
+	TagsRemoved []string    `json:"tags_removed" xml:"tags_removed>tag" yaml:"tags_removed"`
+}
+
+// visibleArticle writes a 404 unless the article can be read at
+// GET /articles/{id}, the revisions of deleted and unpublished articles are as
+// hidden as the articles themselves.
+func (app *App) visibleArticle(w http.ResponseWriter, r *http.Request, id string) bool {
+	_, _, err := app.reader(r).GetArticleByID(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return false
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return false
+	}
+	return true
+}
+
+func (app *App) getRevisionsFunction(w http.ResponseWriter, r *http.Request) {
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.visibleArticle(w, r, id) {
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := app.repo.GetRevisions(id)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	if len(revisions) == 0 {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	}
+
+	response := RevisionsResponse{
+		ArticleId: id,
+		Count:     len(revisions),
+	}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, revisionResponse(r, revision, loc))
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) getRevisionFunction(w http.ResponseWriter, r *http.Request) {
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.visibleArticle(w, r, id) {
+		return
+	}
+
+	revision, ok := app.revisionFromPath(w, id, mux.Vars(r)["revision"])
+	if !ok {
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeResponse(w, r, http.StatusOK, revisionResponse(r, revision, loc))
+}
+
+func (app *App) getRevisionDiffFunction(w http.ResponseWriter, r *http.Request) {
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.visibleArticle(w, r, id) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	from, ok := app.revisionFromPath(w, id, vars["from"])
+	if !ok {
+		return
+	}
+
+	to, ok := app.revisionFromPath(w, id, vars["to"])
+	if !ok {
+		return
+	}
+
+	title, err := diff.Words(from.Title, to.Title)
+	if err != nil {
+		err = handleError(w, "the revisions differ too much to compare", http.StatusUnprocessableEntity)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	body, err := diff.Words(from.Body, to.Body)
+	if err != nil {
+		err = handleError(w, "the revisions differ too much to compare", http.StatusUnprocessableEntity)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := RevisionDiffResponse{
+		ArticleId:   id,
+		From:        from.Revision,
+		To:          to.Revision,
+		Title:       diffEdits(title),
+		Body:        diffEdits(body),
+		TagsAdded:   missingTags(to.Tags, from.Tags),
+		TagsRemoved: missingTags(from.Tags, to.Tags),
+	}
+
+	if !from.Date.Equal(to.Date) {
+		response.Date = &DateChange{
+			From: articleDate(r, from.Date),
+			To:   articleDate(r, to.Date),
+		}
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) restoreRevisionFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, CreateArticleResponse{}); !ok {
+		return
+	}
+
+	id, ok := app.idFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	revision, ok := app.revisionFromPath(w, id, mux.Vars(r)["revision"])
+	if !ok {
+		return
+	}
+
+	current, _, err := app.repo.GetAdminArticleByID(id)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "article not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("article not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	if !app.permitArticle(w, r, policy.ActionUpdate, current.Id) {
+		return
+	}
+
+	if !app.checkIfMatch(w, r, current) {
+		return
+	}
+
+	app.updateArticle(w, r, model.Article{
+		Id:        revision.ArticleId,
+		Title:     revision.Title,
+		Date:      revision.Date,
+		Body:      revision.Body,
+		Status:    current.Status,
+		PublishAt: current.PublishAt,
+		Version:   current.Version,
+	}, revision.Tags, nil)
+}
+
+// revisionFromPath loads the numbered revision of the article, any problem is
+// written to w and reported as false.
+func (app *App) revisionFromPath(w http.ResponseWriter, articleID, number string) (*model.Revision, bool) {
+
+	revisionNumber, err := strconv.Atoi(number)
+	if err != nil {
+		err = handleError(w, "provided revision is not a number", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("provided revision is not a number")
+		}
+		return nil, false
+	}
+
+	revision, err := app.repo.GetRevision(articleID, revisionNumber)
+	if err == sql.ErrNoRows {
+		err = handleError(w, fmt.Sprintf("revision %d not found", revisionNumber), http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("revision %d not found", revisionNumber)
+		}
+		return nil, false
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return nil, false
+	}
+
+	return revision, true
+}
+
+func revisionResponse(r *http.Request, revision *model.Revision, loc *time.Location) Revision {
+	return Revision{
+		Revision:  revision.Revision,
+		ArticleId: fmt.Sprintf("%d", revision.ArticleId),
+		Title:     revision.Title,
+		Date:      articleDate(r, revision.Date),
+		Body:      revision.Body,
+		Tags:      revision.Tags,
+		Author:    revision.Author,
+		CreatedAt: formatTimestamp(revision.CreatedAt, loc),
+	}
+}
+
+func diffEdits(edits []*diff.Edit) []DiffEdit {
+	result := []DiffEdit{}
+	for _, edit := range edits {
+		result = append(result, DiffEdit{Op: edit.Op, Text: edit.Text})
+	}
+	return result
+}
+
+// missingTags returns the tags in a that are not in b
+func missingTags(a, b []string) []string {
+	present := make(map[string]bool)
+	for _, tag := range b {
+		present[tag] = true
+	}
+
+	result := []string{}
+	for _, tag := range a {
+		if !present[tag] {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
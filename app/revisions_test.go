@@ -0,0 +1,132 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/diff"
+	"rest-article/repo"
+	"rest-article/search"
+	"testing"
+)
+
+func TestPutArticleFunction(t *testing.T) {
+	app := newTestApp("TestPutArticleFunction")
+
+	body, _ := json.Marshal(Article{
+		Title: "updated article",
+		Date:  "2020-02-01",
+		Body:  "updated body",
+		Tags:  []string{"science"},
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/articles/1", bytes.NewReader(body))
+	req.Header.Set(HeaderIfMatch, `"1-0000000000000000"`)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody CreateArticleResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 1, respBody.Id)
+
+	results, _ := app.index.Search(search.Query{Text: "updated"})
+	assert.Len(t, results, 1)
+}
+
+func TestPutArticleFunctionMismatchedId(t *testing.T) {
+	app := newTestApp("TestPutArticleFunctionMismatchedId")
+
+	body, _ := json.Marshal(Article{
+		Id:    "2",
+		Title: "updated article",
+		Date:  "2020-02-01",
+		Body:  "updated body",
+		Tags:  []string{"science"},
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/articles/1", bytes.NewReader(body))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, ResponseError("id in body does not match id in path"), respBody.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestGetRevisionsFunction(t *testing.T) {
+	app := newTestApp("TestGetRevisionsFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1/revisions", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody RevisionsResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, respBody.Count)
+	assert.Equal(t, "tester", respBody.Revisions[0].Author)
+}
+
+func TestGetRevisionsOfDeletedArticle(t *testing.T) {
+	app := newTestApp("TestGetRevisionsOfDeletedArticle")
+
+	for _, path := range []string{"/revisions", "/revisions/1", "/revisions/1/diff/2"} {
+		req := httptest.NewRequest(http.MethodGet, "/articles/"+repo.MockDeletedArticleID+path, nil)
+		resp := httptest.NewRecorder()
+		app.Router.ServeHTTP(resp, req)
+
+		var respBody ErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code, path)
+		assert.Equal(t, ResponseError("article not found"), respBody.Error, path)
+	}
+}
+
+func TestGetRevisionDiffFunction(t *testing.T) {
+	app := newTestApp("TestGetRevisionDiffFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1/revisions/1/diff/2", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody RevisionDiffResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []DiffEdit{
+		{Op: diff.OpEqual, Text: "test article revision"},
+		{Op: diff.OpDelete, Text: "1"},
+		{Op: diff.OpInsert, Text: "2"},
+	}, respBody.Title)
+	assert.Equal(t, []string{"test2"}, respBody.TagsAdded)
+	assert.Equal(t, []string{"test1"}, respBody.TagsRemoved)
+	assert.NotNil(t, respBody.Date)
+}
+
+func TestRestoreRevisionFunction(t *testing.T) {
+	app := newTestApp("TestRestoreRevisionFunction")
+
+	req := httptest.NewRequest(http.MethodPost, "/articles/1/revisions/1/restore", nil)
+	req.Header.Set(HeaderIfMatch, `"1-0000000000000000"`)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	results, _ := app.index.Search(search.Query{Text: "revision"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "test article revision 1", results[0].Title)
+}
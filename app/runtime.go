@@ -0,0 +1,47 @@
+package app
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Runtime holds the settings of the API that can change while it serves. They
+// are replaced as a whole, so a request sees either the old settings or the new
+// ones and never a mix of both.
+type Runtime struct {
+	RateLimits  *RateLimits
+	CORS        *CORS
+	Compression *Compression
+}
+
+// runtimeValue is the current Runtime of the app
+type runtimeValue struct {
+	mu    sync.Mutex
+	value atomic.Value
+}
+
+func (rv *runtimeValue) load() Runtime {
+	runtime, _ := rv.value.Load().(Runtime)
+	return runtime
+}
+
+// update changes the settings through change, updates do not overlap
+func (rv *runtimeValue) update(change func(runtime *Runtime)) {
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+
+	runtime := rv.load()
+	change(&runtime)
+	rv.value.Store(runtime)
+}
+
+// Runtime returns the settings the API currently serves with
+func (app *App) Runtime() Runtime {
+	return app.runtime.load()
+}
+
+// SetRuntime replaces the rate limits, cross origin policy and compression at
+// once, requests in flight finish with the settings they started with.
+func (app *App) SetRuntime(runtime Runtime) {
+	app.runtime.update(func(current *Runtime) { *current = runtime })
+}
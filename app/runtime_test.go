@@ -0,0 +1,33 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetRuntime(t *testing.T) {
+	app := newTestApp("TestSetRuntime")
+	compression := &Compression{MinSize: 1}
+	app.SetCompression(compression)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+		req.Header.Set(HeaderOrigin, "https://editor.example.com")
+		resp := httptest.NewRecorder()
+		app.Router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Empty(t, get().Header().Get(HeaderAccessControlAllowOrigin))
+
+	// the routes are set up once, the new settings apply to the next request
+	app.SetCORS(&CORS{AllowedOrigins: []string{"https://editor.example.com"}})
+	assert.Equal(t, "https://editor.example.com", get().Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Equal(t, compression, app.Runtime().Compression)
+
+	app.SetRuntime(Runtime{})
+	assert.Empty(t, get().Header().Get(HeaderAccessControlAllowOrigin))
+	assert.Nil(t, app.Runtime().Compression)
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"rest-article/database/model"
+	"rest-article/search"
+	"strconv"
+)
+
+// maxSearchLimit caps the number of results a single search can return
+const maxSearchLimit = 100
+
+type SearchResult struct {
+	Id      string  `json:"id" xml:"id" yaml:"id"`
+	Title   string  `json:"title" xml:"title" yaml:"title"`
+	Date    string  `json:"date" xml:"date" yaml:"date"`
+	Score   float64 `json:"score" xml:"score" yaml:"score"`
+	Snippet string  `json:"snippet" xml:"snippet" yaml:"snippet"`
+}
+
+type SearchResponse struct {
+	XMLName xml.Name       `json:"-" xml:"search" yaml:"-"`
+	Query   string         `json:"query" xml:"query" yaml:"query"`
+	Count   int            `json:"count" xml:"count" yaml:"count"`
+	Results []SearchResult `json:"results" xml:"result" yaml:"results"`
+}
+
+func (app *App) searchFunction(w http.ResponseWriter, r *http.Request) {
+
+	params := r.URL.Query()
+	query := search.Query{
+		Text: params.Get("q"),
+		Tag:  params.Get("tag"),
+	}
+
+	if query.Text == "" {
+		err := handleError(w, "no search query provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no search query provided")
+		}
+		return
+	}
+
+	loc, ok := app.locationFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if from := params.Get("from"); from != "" {
+		date, err := parseDate(from, loc)
+		if err != nil {
+			err = handleError(w, "bad from date format provided", http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("bad from date format provided")
+			}
+			return
+		}
+		query.From = date
+	}
+
+	if to := params.Get("to"); to != "" {
+		date, err := parseDate(to, loc)
+		if err != nil {
+			err = handleError(w, "bad to date format provided", http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("bad to date format provided")
+			}
+			return
+		}
+		query.To = date
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > maxSearchLimit {
+			err = handleError(w, fmt.Sprintf("limit must be a number between 1 and %d", maxSearchLimit), http.StatusBadRequest)
+			if err != nil {
+				app.logger.Errorf("bad search limit provided")
+			}
+			return
+		}
+		query.Limit = value
+	}
+
+	results, err := app.index.Search(query)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := SearchResponse{
+		Query:   query.Text,
+		Count:   len(results),
+		Results: []SearchResult{},
+	}
+	for _, result := range results {
+		response.Results = append(response.Results, SearchResult{
+			Id:      fmt.Sprintf("%d", result.Id),
+			Title:   result.Title,
+			Date:    articleDate(r, result.Date),
+			Score:   result.Score,
+			Snippet: result.Snippet,
+		})
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+// seedPageSize is the number of articles indexed at once by SeedIndex
+const seedPageSize = 100
+
+// SeedIndex indexes every published article, for indexes such as the in
+// process one that do not keep their content between runs. It returns the
+// number of articles indexed.
+func (app *App) SeedIndex() (int, error) {
+	primary := app.repo.Primary()
+
+	count := 0
+	for offset := 0; ; offset += seedPageSize {
+		articles, err := primary.ListArticles(model.ArticleFilter{Limit: seedPageSize, Offset: offset})
+		if err != nil {
+			return count, err
+		}
+
+		ids := make([]int, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.Id)
+		}
+		tags, err := primary.GetArticleTags(ids)
+		if err != nil {
+			return count, err
+		}
+
+		for _, article := range articles {
+			if err := app.index.Index(*article, tags[article.Id]); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(articles) < seedPageSize {
+			return count, nil
+		}
+	}
+}
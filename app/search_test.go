@@ -0,0 +1,70 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/database/model"
+	"rest-article/search"
+	"testing"
+	"time"
+)
+
+func TestSearchFunction(t *testing.T) {
+	index := search.NewMemoryIndex()
+	_ = index.Index(model.Article{
+		Id:    1,
+		Title: "running for beginners",
+		Date:  time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Body:  "how to start running",
+	}, []string{"fitness"})
+	_ = index.Index(model.Article{
+		Id:    2,
+		Title: "baking bread",
+		Date:  time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC),
+		Body:  "running out of flour",
+	}, []string{"cooking"})
+
+	app := newTestApp("TestSearchFunction", withIndex(index))
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=running&tag=fitness", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody SearchResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 1, respBody.Count)
+	assert.Equal(t, "1", respBody.Results[0].Id)
+	assert.Contains(t, respBody.Results[0].Snippet, search.HighlightStart+"running"+search.HighlightEnd)
+}
+
+func TestSearchFunctionNoQuery(t *testing.T) {
+	app := newTestApp("TestSearchFunctionNoQuery")
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, ResponseError("no search query provided"), respBody.Error)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestSeedIndex(t *testing.T) {
+	index := search.NewMemoryIndex()
+	app := newTestApp("TestSeedIndex", withIndex(index))
+
+	count, err := app.SeedIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	results, _ := index.Search(search.Query{Text: "article", Tag: "test2"})
+	assert.Len(t, results, 3)
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type TagCount struct {
+	Id       string `json:"id" xml:"id" yaml:"id"`
+	Name     string `json:"name" xml:"name" yaml:"name"`
+	Articles int    `json:"articles" xml:"articles" yaml:"articles"`
+}
+
+type TagCountsResponse struct {
+	XMLName xml.Name   `json:"-" xml:"tags" yaml:"-"`
+	Count   int        `json:"count" xml:"count" yaml:"count"`
+	Tags    []TagCount `json:"tags" xml:"tag" yaml:"tags"`
+}
+
+type MergeTagsRequest struct {
+	Into string `json:"into"`
+}
+
+type MergeTagsResponse struct {
+	XMLName xml.Name `json:"-" xml:"merge" yaml:"-"`
+	Success bool     `json:"success" xml:"success" yaml:"success"`
+	Tag     string   `json:"tag" xml:"tag" yaml:"tag"`
+	Moved   int      `json:"moved" xml:"moved" yaml:"moved"`
+}
+
+func (app *App) getTagCountsFunction(w http.ResponseWriter, r *http.Request) {
+
+	tags, err := app.reader(r).GetTagCounts()
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	response := TagCountsResponse{
+		Count: len(tags),
+		Tags:  []TagCount{},
+	}
+	for _, tag := range tags {
+		response.Tags = append(response.Tags, TagCount{
+			Id:       fmt.Sprintf("%d", tag.Id),
+			Name:     tag.Name,
+			Articles: tag.Count,
+		})
+	}
+
+	app.writeResponse(w, r, http.StatusOK, response)
+}
+
+func (app *App) mergeTagsFunction(w http.ResponseWriter, r *http.Request) {
+
+	if _, ok := app.acceptable(w, r, MergeTagsResponse{}); !ok {
+		return
+	}
+
+	from := mux.Vars(r)["tagName"]
+
+	var request MergeTagsRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		err = handleError(w, err.Error(), http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("error decoding json post body because: %v", err)
+		}
+		return
+	}
+
+	if request.Into == "" {
+		err = handleError(w, "no into tag provided", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("no into tag provided")
+		}
+		return
+	}
+
+	if request.Into == from {
+		err = handleError(w, "a tag can not be merged into itself", http.StatusBadRequest)
+		if err != nil {
+			app.logger.Errorf("a tag can not be merged into itself")
+		}
+		return
+	}
+
+	moved, err := app.repo.MergeTags(from, request.Into)
+	if err == sql.ErrNoRows {
+		err = handleError(w, "tag not found", http.StatusNotFound)
+		if err != nil {
+			app.logger.Errorf("tag not found")
+		}
+		return
+	} else if err != nil {
+		err = handleError(w, err.Error(), http.StatusInternalServerError)
+		if err != nil {
+			app.logger.Errorf("error sending error response because: %v", err)
+		}
+		return
+	}
+
+	app.writeResponse(w, r, http.StatusOK, MergeTagsResponse{
+		Success: true,
+		Tag:     request.Into,
+		Moved:   moved,
+	})
+}
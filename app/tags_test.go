@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/auth"
+	"strings"
+	"testing"
+)
+
+func TestGetTagCountsFunction(t *testing.T) {
+	app := newTestApp("TestGetTagCountsFunction")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/tags", nil)
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody TagCountsResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 2, respBody.Count)
+	assert.Equal(t, TagCount{Id: "1", Name: "test", Articles: 3}, respBody.Tags[0])
+}
+
+func TestGetTagCountsFunctionMissingScope(t *testing.T) {
+	app := newTestApp("TestGetTagCountsFunctionMissingScope", withStaticKeys(adminKey))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/tags", nil)
+	req.Header.Set(auth.HeaderAPIKey, "admin-key")
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, ResponseError("missing scope tags:admin"), respBody.Error)
+}
+
+func TestMergeTagsFunction(t *testing.T) {
+	app := newTestApp("TestMergeTagsFunction")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/tags/test/merge", strings.NewReader(`{"into": "science"}`))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody MergeTagsResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, MergeTagsResponse{Success: true, Tag: "science", Moved: 3}, respBody)
+}
+
+func TestMergeTagsFunctionBadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no into", `{}`},
+		{"into itself", `{"into": "test"}`},
+		{"bad json", `{`},
+	}
+
+	app := newTestApp("TestMergeTagsFunctionBadRequest")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/tags/test/merge", strings.NewReader(test.body))
+			resp := httptest.NewRecorder()
+
+			app.Router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestMergeTagsFunctionNotFound(t *testing.T) {
+	app := newTestApp("TestMergeTagsFunctionNotFound")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/tags/unknown/merge", strings.NewReader(`{"into": "test"}`))
+	resp := httptest.NewRecorder()
+
+	app.Router.ServeHTTP(resp, req)
+
+	var respBody ErrorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&respBody)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, ResponseError("tag not found"), respBody.Error)
+}
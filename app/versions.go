@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API version constants
+const (
+	Version1 = 1
+	Version2 = 2
+)
+
+// Version header constants
+const (
+	HeaderAccept      = "Accept"
+	HeaderAPIVersion  = "API-Version"
+	HeaderDeprecation = "Deprecation"
+	HeaderLink        = "Link"
+)
+
+// v1DateFormat is the article date format of v1 responses
+const v1DateFormat = "01-02-2006"
+
+// vendorMediaType matches the versioned media types of the API, such as
+// application/vnd.rest-article.v2+json
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.rest-article\.v(\d+)(\+[a-z]+)?$`)
+
+type contextKey string
+
+const versionKey = contextKey("version")
+
+// withVersion serves every request in the API version
+func (app *App) withVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(HeaderAPIVersion, strconv.Itoa(version))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionKey, version)))
+		})
+	}
+}
+
+// negotiateVersion serves unversioned paths in the API version asked for in the
+// Accept header. Requests without one are served as v1 and told that the path
+// is deprecated.
+func (app *App) negotiateVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addVary(w, HeaderAccept)
+
+		version, err := acceptVersion(r.Header.Get(HeaderAccept))
+		if err != nil {
+			err = handleError(w, err.Error(), http.StatusNotAcceptable)
+			if err != nil {
+				app.logger.Errorf("error sending error response because: %v", err)
+			}
+			return
+		}
+
+		if version == 0 {
+			version = Version1
+			w.Header().Set(HeaderDeprecation, "true")
+			w.Header().Set(HeaderLink, fmt.Sprintf(`</v%d%s>; rel="successor-version"`, Version2, r.URL.Path))
+		}
+
+		app.withVersion(version)(next).ServeHTTP(w, r)
+	})
+}
+
+// acceptVersion returns the API version named in the Accept header, either as
+// a vendor media type or a version parameter, and 0 when there is none.
+func acceptVersion(accept string) (int, error) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		value := params["version"]
+		if match := vendorMediaType.FindStringSubmatch(mediaType); match != nil {
+			value = match[1]
+		}
+		if value == "" {
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.TrimPrefix(value, "v"))
+		if err != nil || version < Version1 || version > Version2 {
+			return 0, fmt.Errorf("unsupported api version %s", value)
+		}
+		return version, nil
+	}
+
+	return 0, nil
+}
+
+// requestVersion returns the API version the request is served in
+func requestVersion(r *http.Request) int {
+	if version, ok := r.Context().Value(versionKey).(int); ok {
+		return version
+	}
+	return Version1
+}
+
+// articleDate formats an article date for the API version of the request, v1
+// keeps its original month first format.
+func articleDate(r *http.Request, date time.Time) string {
+	if requestVersion(r) == Version1 {
+		return date.Format(v1DateFormat)
+	}
+	return formatDate(date)
+}
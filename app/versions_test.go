@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersion1Payloads(t *testing.T) {
+	app := newTestApp("TestVersion1Payloads")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/articles?limit=1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(HeaderAPIVersion))
+	assert.Empty(t, resp.Header().Get(HeaderDeprecation))
+
+	var list ArticlesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	assert.Equal(t, "02-01-2020", list.Articles[0].Date)
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/tag/science/2020-02-01", nil)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var summary map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Contains(t, summary, "related_tag")
+	assert.NotContains(t, summary, "related_tags")
+}
+
+func TestVersion2Payloads(t *testing.T) {
+	app := newTestApp("TestVersion2Payloads")
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/articles?limit=1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "2", resp.Header().Get(HeaderAPIVersion))
+
+	var list ArticlesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	assert.Equal(t, "2020-02-01", list.Articles[0].Date)
+
+	req = httptest.NewRequest(http.MethodGet, "/v2/tag/science/2020-02-01", nil)
+	resp = httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var summary TagSummaryResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal(t, []string{"test", "test2"}, summary.RelatedTags)
+}
+
+func TestUnversionedPathIsDeprecated(t *testing.T) {
+	app := newTestApp("TestUnversionedPathIsDeprecated")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles?limit=1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get(HeaderAPIVersion))
+	assert.Equal(t, "true", resp.Header().Get(HeaderDeprecation))
+	assert.Equal(t, `</v2/articles>; rel="successor-version"`, resp.Header().Get(HeaderLink))
+
+	var list ArticlesResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	assert.Equal(t, "02-01-2020", list.Articles[0].Date)
+}
+
+func TestAcceptVersion(t *testing.T) {
+	app := newTestApp("TestAcceptVersion")
+
+	for _, accept := range []string{"application/vnd.rest-article.v2+json", "application/json; version=2"} {
+		req := httptest.NewRequest(http.MethodGet, "/articles?limit=1", nil)
+		req.Header.Set(HeaderAccept, accept)
+		resp := httptest.NewRecorder()
+		app.Router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code, accept)
+		assert.Equal(t, "2", resp.Header().Get(HeaderAPIVersion), accept)
+		assert.Empty(t, resp.Header().Get(HeaderDeprecation), accept)
+
+		var list ArticlesResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		assert.Equal(t, "2020-02-01", list.Articles[0].Date)
+	}
+}
+
+func TestAcceptVersionUnsupported(t *testing.T) {
+	app := newTestApp("TestAcceptVersionUnsupported")
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set(HeaderAccept, "application/vnd.rest-article.v3+json")
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotAcceptable, resp.Code)
+
+	var respBody ErrorResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+	assert.Equal(t, ResponseError("unsupported api version 3"), respBody.Error)
+}
+
+func TestVersionedPreflight(t *testing.T) {
+	app := newTestApp("TestVersionedPreflight")
+
+	req := httptest.NewRequest(http.MethodOptions, "/v2/articles/1", nil)
+	resp := httptest.NewRecorder()
+	app.Router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, "GET, PUT, DELETE, OPTIONS", resp.Header().Get(HeaderAllow))
+}
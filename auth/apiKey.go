@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// keyPrefix marks generated keys so they are easy to spot in leaked text
+const keyPrefix = "ra_"
+
+// GenerateKey returns a new random API key and its hash
+func GenerateKey() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, HashKey(key), nil
+}
+
+// HashKey returns the hex encoded SHA-256 hash an API key is stored as
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
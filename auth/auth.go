@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"rest-article/database/model"
+	"strings"
+)
+
+// Scope constants
+const (
+	ScopeArticlesRead  = "articles:read"
+	ScopeArticlesWrite = "articles:write"
+	ScopeArticlesAdmin = "articles:admin"
+	ScopeTagsAdmin     = "tags:admin"
+	ScopeKeysAdmin     = "keys:admin"
+)
+
+// Role constants, roles decide what a principal may change, see the policy package
+const (
+	RoleReader = "reader"
+	RoleAuthor = "author"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Authentication method constants
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Header constants
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderAPIKey        = "X-API-Key"
+)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials provided")
+	ErrInvalidCredentials = errors.New("invalid credentials provided")
+)
+
+// Principal is the authenticated caller of a request, AuthorId links principals
+// with the author role to their author.
+type Principal struct {
+	Subject  string
+	Method   string
+	Role     string
+	AuthorId int
+	Scopes   []string
+}
+
+// HasScope reports whether the principal was granted the scope
+func (principal *Principal) HasScope(scope string) bool {
+	for _, granted := range principal.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// StaticKey is an API key configured up front, only the SHA-256 hash of the key
+// is kept.
+type StaticKey struct {
+	Name     string
+	Hash     string
+	Role     string
+	AuthorId int
+	Scopes   []string
+}
+
+// KeyStore looks up API keys managed at runtime
+type KeyStore interface {
+	GetAPIKeyByHash(hash string) (*model.APIKey, error)
+}
+
+// Authenticator checks the API key or JWT bearer token of a request
+type Authenticator struct {
+	staticKeys []StaticKey
+	store      KeyStore
+	jwt        *JWTVerifier
+}
+
+// NewAuthenticator returns an authenticator accepting the static keys, keys in
+// the store and tokens accepted by the verifier, store and verifier may be nil.
+func NewAuthenticator(staticKeys []StaticKey, store KeyStore, verifier *JWTVerifier) *Authenticator {
+	return &Authenticator{
+		staticKeys: staticKeys,
+		store:      store,
+		jwt:        verifier,
+	}
+}
+
+// Authenticate returns the principal for the credentials on the request
+func (authenticator *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return authenticator.authenticateKey(key)
+	}
+
+	header := r.Header.Get(HeaderAuthorization)
+	if header == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || parts[1] == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	token := strings.TrimSpace(parts[1])
+	if strings.Count(token, ".") == 2 {
+		if authenticator.jwt == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return authenticator.jwt.Verify(token)
+	}
+
+	return authenticator.authenticateKey(token)
+}
+
+func (authenticator *Authenticator) authenticateKey(key string) (*Principal, error) {
+	hash := HashKey(key)
+
+	for _, staticKey := range authenticator.staticKeys {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(strings.ToLower(staticKey.Hash))) == 1 {
+			return &Principal{
+				Subject:  staticKey.Name,
+				Method:   MethodAPIKey,
+				Role:     staticKey.Role,
+				AuthorId: staticKey.AuthorId,
+				Scopes:   staticKey.Scopes,
+			}, nil
+		}
+	}
+
+	if authenticator.store == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	apiKey, err := authenticator.store.GetAPIKeyByHash(hash)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{
+		Subject:  apiKey.Name,
+		Method:   MethodAPIKey,
+		Role:     apiKey.Role,
+		AuthorId: apiKey.AuthorId,
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
+
+type contextKey string
+
+const principalKey = contextKey("principal")
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFrom returns the principal stored on ctx, if any
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey).(*Principal)
+	return principal
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signToken(t *testing.T, alg string, claims map[string]interface{}, sign func([]byte) []byte) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(secret string) func([]byte) []byte {
+	return func(data []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(data)
+		return mac.Sum(nil)
+	}
+}
+
+func TestJWTVerifierHS256(t *testing.T) {
+	verifier, err := NewJWTVerifier("secret", nil, "issuer", "rest-article")
+	assert.Nil(t, err)
+
+	token := signToken(t, AlgorithmHS256, map[string]interface{}{
+		"sub":   "andrew",
+		"iss":   "issuer",
+		"aud":   []string{"other", "rest-article"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "articles:read articles:write",
+	}, hs256("secret"))
+
+	principal, err := verifier.Verify(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "andrew", principal.Subject)
+	assert.True(t, principal.HasScope(ScopeArticlesWrite))
+	assert.False(t, principal.HasScope(ScopeKeysAdmin))
+}
+
+func TestJWTVerifierRejectsBadTokens(t *testing.T) {
+	verifier, _ := NewJWTVerifier("secret", nil, "", "")
+
+	expired := signToken(t, AlgorithmHS256, map[string]interface{}{
+		"sub": "andrew",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}, hs256("secret"))
+	_, err := verifier.Verify(expired)
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+
+	wrongSecret := signToken(t, AlgorithmHS256, map[string]interface{}{
+		"sub": "andrew",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, hs256("other"))
+	_, err = verifier.Verify(wrongSecret)
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+
+	unsigned := signToken(t, "none", map[string]interface{}{
+		"sub": "andrew",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, func([]byte) []byte { return nil })
+	_, err = verifier.Verify(unsigned)
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+}
+
+func TestJWTVerifierRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	publicKey, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+
+	verifier, err := NewJWTVerifier("", publicKeyPEM, "", "")
+	assert.Nil(t, err)
+
+	token := signToken(t, AlgorithmRS256, map[string]interface{}{
+		"sub":    "andrew",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"scopes": []string{ScopeArticlesRead},
+	}, func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		return signature
+	})
+
+	principal, err := verifier.Verify(token)
+	assert.Nil(t, err)
+	assert.True(t, principal.HasScope(ScopeArticlesRead))
+
+	hsToken := signToken(t, AlgorithmHS256, map[string]interface{}{
+		"sub": "andrew",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}, hs256(""))
+	_, err = verifier.Verify(hsToken)
+	assert.True(t, errors.Is(err, ErrInvalidCredentials))
+}
+
+func TestAuthenticatorStaticKey(t *testing.T) {
+	authenticator := NewAuthenticator([]StaticKey{
+		{Name: "admin", Hash: HashKey("secret-key"), Scopes: []string{ScopeKeysAdmin}},
+	}, nil, nil)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(HeaderAuthorization, "Bearer secret-key")
+	principal, err := authenticator.Authenticate(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", principal.Subject)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(HeaderAPIKey, "wrong-key")
+	_, err = authenticator.Authenticate(req)
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	_, err = authenticator.Authenticate(req)
+	assert.Equal(t, ErrNoCredentials, err)
+}
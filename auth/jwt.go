@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Algorithm constants
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// leeway allows for clock drift between the token issuer and this service
+const leeway = 30 * time.Second
+
+// JWTVerifier checks HS256 and RS256 signed bearer tokens against locally
+// configured keys.
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	now       func() time.Time
+}
+
+// NewJWTVerifier returns a verifier for tokens signed with the HS256 secret or
+// the RS256 key, either may be empty to disable that algorithm.
+func NewJWTVerifier(secret string, publicKeyPEM []byte, issuer, audience string) (*JWTVerifier, error) {
+	verifier := &JWTVerifier{
+		secret:   []byte(secret),
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+
+	if len(publicKeyPEM) > 0 {
+		publicKey, err := ParseRSAPublicKey(publicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		verifier.publicKey = publicKey
+	}
+
+	if len(verifier.secret) == 0 && verifier.publicKey == nil {
+		return nil, errors.New("no jwt signing keys configured")
+	}
+
+	return verifier, nil
+}
+
+// ParseRSAPublicKey reads a PEM encoded PKIX or PKCS1 RSA public key
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in public key")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+
+	return publicKey, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *float64        `json:"exp"`
+	NotBefore *float64        `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scopes    []string        `json:"scopes"`
+	Role      string          `json:"role"`
+	AuthorId  int             `json:"author_id"`
+}
+
+// Verify checks the signature and claims of the token and returns its principal
+func (verifier *JWTVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case AlgorithmHS256:
+		if len(verifier.secret) == 0 {
+			return nil, ErrInvalidCredentials
+		}
+		mac := hmac.New(sha256.New, verifier.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, ErrInvalidCredentials
+		}
+	case AlgorithmRS256:
+		if verifier.publicKey == nil {
+			return nil, ErrInvalidCredentials
+		}
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(verifier.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return nil, ErrInvalidCredentials
+		}
+	default:
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := verifier.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	scopes := claims.Scopes
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &Principal{
+		Subject:  claims.Subject,
+		Method:   MethodJWT,
+		Role:     claims.Role,
+		AuthorId: claims.AuthorId,
+		Scopes:   scopes,
+	}, nil
+}
+
+func (verifier *JWTVerifier) checkClaims(claims *jwtClaims) error {
+	now := verifier.now()
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+	if now.After(time.Unix(int64(*claims.ExpiresAt), 0).Add(leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidCredentials)
+	}
+
+	if claims.NotBefore != nil && now.Add(leeway).Before(time.Unix(int64(*claims.NotBefore), 0)) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidCredentials)
+	}
+
+	if verifier.issuer != "" && claims.Issuer != verifier.issuer {
+		return fmt.Errorf("%w: unexpected token issuer", ErrInvalidCredentials)
+	}
+
+	if verifier.audience != "" && !hasAudience(claims.Audience, verifier.audience) {
+		return fmt.Errorf("%w: unexpected token audience", ErrInvalidCredentials)
+	}
+
+	return nil
+}
+
+// hasAudience matches the aud claim, which may be a single string or a list
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, value := range list {
+			if value == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Article is an article as sent and returned by the API
+type Article struct {
+	Id        string   `json:"id"`
+	Title     string   `json:"title"`
+	Date      string   `json:"date"`
+	Body      string   `json:"body"`
+	Tags      []string `json:"tags"`
+	Status    string   `json:"status,omitempty"`
+	PublishAt string   `json:"publish_at,omitempty"`
+	AuthorIds []string `json:"author_ids,omitempty"`
+	Authors   []Author `json:"authors,omitempty"`
+}
+
+type Author struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Bio   string `json:"bio,omitempty"`
+}
+
+type ArticleList struct {
+	Count    int       `json:"count"`
+	Articles []Article `json:"articles"`
+}
+
+type TagSummary struct {
+	Tag         string   `json:"tag"`
+	Count       int      `json:"count"`
+	Articles    []string `json:"articles"`
+	RelatedTags []string `json:"related_tags"`
+}
+
+type SearchResult struct {
+	Id      string  `json:"id"`
+	Title   string  `json:"title"`
+	Date    string  `json:"date"`
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+type SearchResults struct {
+	Query   string         `json:"query"`
+	Count   int            `json:"count"`
+	Results []SearchResult `json:"results"`
+}
+
+type createArticleResponse struct {
+	Success bool `json:"success"`
+	Id      int  `json:"id"`
+}
+
+// ListOptions filter and page article listings, zero values are left out
+type ListOptions struct {
+	AuthorId int
+	Tag      string
+	Limit    int
+	Offset   int
+}
+
+// SearchOptions narrow a search, only Query is required
+type SearchOptions struct {
+	Query string
+	Tag   string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// GetArticle fetches a published article
+func (client *Client) GetArticle(ctx context.Context, id int) (*Article, error) {
+	var article Article
+	err := client.do(ctx, http.MethodGet, fmt.Sprintf("/articles/%d", id), nil, nil, &article)
+	if err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// CreateArticle stores the article and returns its id
+func (client *Client) CreateArticle(ctx context.Context, article Article) (int, error) {
+	var response createArticleResponse
+	err := client.do(ctx, http.MethodPost, "/articles", nil, article, &response)
+	if err != nil {
+		return 0, err
+	}
+	return response.Id, nil
+}
+
+// ListArticles lists published articles newest first
+func (client *Client) ListArticles(ctx context.Context, options ListOptions) (*ArticleList, error) {
+	query := url.Values{}
+	if options.AuthorId != 0 {
+		query.Set("author", strconv.Itoa(options.AuthorId))
+	}
+	if options.Tag != "" {
+		query.Set("tag", options.Tag)
+	}
+	if options.Limit != 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	if options.Offset != 0 {
+		query.Set("offset", strconv.Itoa(options.Offset))
+	}
+
+	var list ArticleList
+	err := client.do(ctx, http.MethodGet, "/articles", query, nil, &list)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// TagSummary summarises the published articles of a tag on a day
+func (client *Client) TagSummary(ctx context.Context, tag string, date time.Time) (*TagSummary, error) {
+	path := fmt.Sprintf("/tag/%s/%s", url.PathEscape(tag), date.Format("2006-01-02"))
+
+	var summary TagSummary
+	err := client.do(ctx, http.MethodGet, path, nil, nil, &summary)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Search runs a full text search of the published articles
+func (client *Client) Search(ctx context.Context, options SearchOptions) (*SearchResults, error) {
+	query := url.Values{}
+	query.Set("q", options.Query)
+	if options.Tag != "" {
+		query.Set("tag", options.Tag)
+	}
+	if !options.From.IsZero() {
+		query.Set("from", options.From.Format("2006-01-02"))
+	}
+	if !options.To.IsZero() {
+		query.Set("to", options.To.Format("2006-01-02"))
+	}
+	if options.Limit != 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+
+	var results SearchResults
+	err := client.do(ctx, http.MethodGet, "/search", query, nil, &results)
+	if err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
@@ -0,0 +1,223 @@
+// Package client is a typed Go client for the rest article API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default retry constants
+const (
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// APIPath is the path of the API version the client speaks, relative to the
+// base url
+const APIPath = "/v2"
+
+// Client calls the rest article API. Failed requests are retried with
+// exponential backoff on 429 responses, and on 5xx responses for every method
+// but POST, which is not idempotent.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests through httpClient instead of
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates every request with the API key
+func WithAPIKey(key string) Option {
+	return func(client *Client) {
+		client.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates every request with the bearer token, either an
+// API key or a JWT.
+func WithBearerToken(token string) Option {
+	return func(client *Client) {
+		client.token = token
+	}
+}
+
+// WithRetries retries failed requests up to maxRetries times, waiting backoff
+// before the first retry and doubling the wait up to maxBackoff.
+func WithRetries(maxRetries int, backoff, maxBackoff time.Duration) Option {
+	return func(client *Client) {
+		client.maxRetries = maxRetries
+		client.backoff = backoff
+		client.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a client for the v2 API served at baseURL
+func New(baseURL string, options ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("base url %s is not absolute", baseURL)
+	}
+	parsed.Path += APIPath
+
+	client := &Client{
+		baseURL:    parsed,
+		httpClient: http.DefaultClient,
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(client)
+	}
+
+	return client, nil
+}
+
+// do sends the request, retrying where allowed, and decodes the response body
+// into out unless out is nil.
+func (client *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = data
+	}
+
+	// path is already escaped, it is appended as is
+	target := client.baseURL.String() + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := client.send(ctx, method, target, body, out)
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || attempt >= client.maxRetries || !retryable(method, apiErr.StatusCode) {
+			return err
+		}
+
+		wait := client.wait(attempt, apiErr.RetryAfter)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (client *Client) send(ctx context.Context, method, target string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.apiKey != "" {
+		req.Header.Set("X-API-Key", client.apiKey)
+	}
+	if client.token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newError(resp)
+	}
+
+	if out == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding %s %s response because: %v", method, target, err)
+	}
+
+	return nil
+}
+
+// wait returns the backoff before the retry following attempt, a Retry-After
+// sent by the service wins when it is longer.
+func (client *Client) wait(attempt int, retryAfter time.Duration) time.Duration {
+	wait := client.backoff << uint(attempt)
+	if wait <= 0 || wait > client.maxBackoff {
+		wait = client.maxBackoff
+	}
+
+	// up to a quarter of jitter keeps clients from retrying in lockstep
+	if quarter := int64(wait / 4); quarter > 0 {
+		wait += time.Duration(rand.Int63n(quarter))
+	}
+
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	if wait > client.maxBackoff {
+		wait = client.maxBackoff
+	}
+
+	return wait
+}
+
+func retryable(method string, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return statusCode >= http.StatusInternalServerError && method != http.MethodPost
+}
+
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
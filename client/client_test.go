@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"rest-article/app"
+	"rest-article/auth"
+	"rest-article/database/model"
+	"rest-article/repo"
+	"rest-article/search"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, err error, authenticator *auth.Authenticator) *httptest.Server {
+	index := search.NewMemoryIndex()
+	_ = index.Index(model.Article{
+		Id:     1,
+		Title:  "Why sleep matters",
+		Date:   time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+		Body:   "sleep helps",
+		Status: model.StatusPublished,
+	}, []string{"science"})
+
+	api := app.NewAppWithRepo(mux.NewRouter(), &repo.ArticleRepoMock{Err: err}, index, authenticator, context.Background())
+	api.SetupRouter()
+
+	server := httptest.NewServer(api.Router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, baseURL string, options ...Option) *Client {
+	options = append([]Option{WithRetries(2, time.Millisecond, 10*time.Millisecond)}, options...)
+	client, err := New(baseURL, options...)
+	if err != nil {
+		t.Fatalf("error creating client: %v", err)
+	}
+	return client
+}
+
+func TestGetArticle(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	article, err := client.GetArticle(context.Background(), 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", article.Id)
+	assert.Equal(t, "test article", article.Title)
+	assert.NotEmpty(t, article.Tags)
+}
+
+func TestCreateArticle(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	id, err := client.CreateArticle(context.Background(), Article{
+		Id:    "7",
+		Title: "test article",
+		Date:  "2020-02-01",
+		Body:  "test body",
+		Tags:  []string{"test"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 7, id)
+}
+
+func TestCreateArticleBadRequest(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	_, err := client.CreateArticle(context.Background(), Article{Id: "7"})
+
+	var apiErr *Error
+	assert.True(t, errors.Is(err, ErrBadRequest))
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "no title provided", apiErr.Message)
+}
+
+func TestListArticles(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	list, err := client.ListArticles(context.Background(), ListOptions{Tag: "test", Limit: 3})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, list.Count)
+	assert.Len(t, list.Articles, 3)
+}
+
+func TestTagSummary(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	summary, err := client.TagSummary(context.Background(), "test", time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test", summary.Tag)
+	assert.Equal(t, 999, summary.Count)
+	assert.Equal(t, []string{"test", "test2"}, summary.RelatedTags)
+}
+
+func TestSearch(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	client := newTestClient(t, server.URL)
+
+	results, err := client.Search(context.Background(), SearchOptions{Query: "sleep", Tag: "science"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, results.Count)
+	assert.Equal(t, "1", results.Results[0].Id)
+}
+
+func TestAuthentication(t *testing.T) {
+	mockRepo := &repo.ArticleRepoMock{}
+	server := newTestServer(t, nil, auth.NewAuthenticator(nil, mockRepo, nil))
+
+	_, err := newTestClient(t, server.URL).GetArticle(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	_, err = newTestClient(t, server.URL, WithAPIKey(repo.MockAPIKey)).GetArticle(context.Background(), 1)
+	assert.Nil(t, err)
+
+	_, err = newTestClient(t, server.URL, WithBearerToken(repo.MockAPIKey)).GetArticle(context.Background(), 1)
+	assert.Nil(t, err)
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	server := newTestServer(t, errors.New("database is down"), nil)
+
+	var calls int32
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer counting.Close()
+
+	_, err := newTestClient(t, counting.URL).GetArticle(context.Background(), 1)
+
+	var apiErr *Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, "database is down", apiErr.Message)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetriesRateLimitedUntilSuccess(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+
+	var calls int32
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"rate limit exceeded"}`))
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer limited.Close()
+
+	id, err := newTestClient(t, limited.URL).CreateArticle(context.Background(), Article{
+		Id:    "7",
+		Title: "test article",
+		Date:  "2020-02-01",
+		Body:  "test body",
+		Tags:  []string{"test"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPostNotRetriedOnServerError(t *testing.T) {
+	server := newTestServer(t, errors.New("database is down"), nil)
+
+	var calls int32
+	counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer counting.Close()
+
+	_, err := newTestClient(t, counting.URL).CreateArticle(context.Background(), Article{
+		Id:    "7",
+		Title: "test article",
+		Date:  "2020-02-01",
+		Body:  "test body",
+		Tags:  []string{"test"},
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestProblemErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"type":"about:blank","title":"Forbidden","status":403,"detail":"readers cannot update an article"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server.URL).GetArticle(context.Background(), 1)
+
+	var apiErr *Error
+	assert.True(t, errors.Is(err, ErrForbidden))
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "readers cannot update an article", apiErr.Message)
+	assert.Equal(t, "Forbidden", apiErr.Problem.Title)
+}
+
+func TestContextCancelled(t *testing.T) {
+	server := newTestServer(t, errors.New("database is down"), nil)
+	client := newTestClient(t, server.URL, WithRetries(5, time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetArticle(ctx, 1)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
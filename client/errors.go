@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadRequest   = &Error{StatusCode: http.StatusBadRequest}
+	ErrUnauthorized = &Error{StatusCode: http.StatusUnauthorized}
+	ErrForbidden    = &Error{StatusCode: http.StatusForbidden}
+	ErrNotFound     = &Error{StatusCode: http.StatusNotFound}
+	ErrRateLimited  = &Error{StatusCode: http.StatusTooManyRequests}
+)
+
+// Error is an error response of the service. Use errors.Is with ErrNotFound and
+// the other sentinel errors to check the kind of error.
+type Error struct {
+	StatusCode int
+	Message    string
+	// Problem is set for RFC 7807 problem bodies, such as policy denials
+	Problem    *Problem
+	RetryAfter time.Duration
+}
+
+// Problem is an RFC 7807 problem body
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+}
+
+type errorBody struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("rest article: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("rest article: %d %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether target is an *Error with the same status code
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.StatusCode == e.StatusCode
+}
+
+// newError reads the error body of resp, both the service's error responses
+// and problem bodies are understood.
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil || len(data) == 0 {
+		return apiErr
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") {
+		var problem Problem
+		if json.Unmarshal(data, &problem) == nil {
+			apiErr.Problem = &problem
+			apiErr.Message = problem.Detail
+			return apiErr
+		}
+	}
+
+	var body errorBody
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message = body.Error
+		return apiErr
+	}
+
+	apiErr.Message = strings.TrimSpace(string(data))
+	return apiErr
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"rest-article/app"
+	"strings"
+)
+
+var articleCommand = &command{
+	name:    "article",
+	args:    "get|create",
+	summary: "read or create a single article",
+	commands: []*command{
+		{name: "get", args: "<id>", summary: "print an article whatever its status", run: getArticle},
+		{name: "create", summary: "create an article from flags or a JSON file", run: createArticle},
+	},
+}
+
+func getArticle(args []string) error {
+	flags := newFlagSet("article get", "<id>")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("article get takes the id of the article")
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	article, err := env.app.AdminArticle(flags.Arg(0))
+	if err == sql.ErrNoRows {
+		return errors.New("article not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return printJSON(article)
+}
+
+// createArticle creates an article given either as a JSON file, in the shape
+// POST /articles takes, or by its fields as flags. It goes through the import
+// so the article is checked, indexed and revisioned the same way.
+func createArticle(args []string) error {
+	flags := newFlagSet("article create", "")
+	file := flags.String("file", "", "JSON file holding the article, - reads stdin")
+	id := flags.String("id", "", "id of the article")
+	title := flags.String("title", "", "title of the article")
+	date := flags.String("date", "", "date of the article, "+app.DateFormat)
+	body := flags.String("body", "", "body of the article")
+	tags := flags.String("tags", "", "comma separated tags of the article")
+	status := flags.String("status", "", "status of the article, published when empty")
+	publishAt := flags.String("publish-at", "", "time a scheduled article is published at, RFC 3339")
+	authorIDs := flags.String("author-ids", "", "comma separated ids of the authors of the article")
+	author := flags.String("author", "cli", "author recorded against the first revision")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("article create takes no arguments")
+	}
+
+	var article app.Article
+	if *file != "" {
+		var input io.Reader = os.Stdin
+		if *file != "-" {
+			f, err := os.Open(*file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			input = f
+		}
+
+		data, err := ioutil.ReadAll(input)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &article); err != nil {
+			return err
+		}
+	} else {
+		article = app.Article{
+			Id:        *id,
+			Title:     *title,
+			Date:      *date,
+			Body:      *body,
+			Tags:      splitList(*tags),
+			Status:    *status,
+			PublishAt: *publishAt,
+			AuthorIds: splitList(*authorIDs),
+		}
+	}
+
+	record, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	report, err := env.app.ImportArticles(bytes.NewReader(record), app.ImportOptions{
+		Format: app.ImportFormatJSONL,
+		Author: *author,
+	})
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		return errors.New(report.Errors[0].Error)
+	}
+
+	created, err := env.app.AdminArticle(article.Id)
+	if err != nil {
+		return err
+	}
+	return printJSON(created)
+}
+
+// splitList splits a comma separated flag, an empty flag is an empty list
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
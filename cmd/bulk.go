@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"rest-article/app"
+	"strings"
+	"time"
+)
+
+var importCommand = &command{
+	name:    "import",
+	args:    "[file]",
+	summary: "import articles from a JSON lines or csv file",
+	run:     importArticles,
+}
+
+var exportCommand = &command{
+	name:    "export",
+	args:    "[file]",
+	summary: "write a dump of every author and article",
+	run:     exportDump,
+}
+
+var restoreCommand = &command{
+	name:    "restore",
+	args:    "[file]",
+	summary: "load a dump written by export",
+	run:     restoreDump,
+}
+
+// errImportFailed reports an import that left records out, the report says
+// which
+var errImportFailed = errors.New("import failed")
+
+// importArticles streams articles from a JSON lines or csv file into the
+// database and prints the import report. Without a file the articles are read
+// from stdin.
+func importArticles(args []string) error {
+	flags := newFlagSet("import", "[file]")
+	format := flags.String("format", "", "format of the input, jsonl or csv, guessed from the file extension when empty")
+	onError := flags.String("on-error", app.OnErrorAbort, "what a bad record does, abort or skip")
+	batchSize := flags.Int("batch-size", app.DefaultImportBatchSize, "number of articles written at once")
+	author := flags.String("author", "import", "author recorded against the first revision of every article")
+	tz := flags.String("tz", "UTC", "timezone of timestamps without an offset")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	skipInvalid, err := app.ParseOnError(*onError)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return usagef("unknown timezone %s provided", *tz)
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+
+		if *format == "" && strings.EqualFold(filepath.Ext(path), ".csv") {
+			*format = app.ImportFormatCSV
+		}
+	}
+	if *format == "" {
+		*format = app.ImportFormatJSONL
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	report, err := env.app.ImportArticles(input, app.ImportOptions{
+		Format:      *format,
+		SkipInvalid: skipInvalid,
+		BatchSize:   *batchSize,
+		Author:      *author,
+		Location:    loc,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if !report.Success {
+		return errImportFailed
+	}
+	return nil
+}
+
+// exportDump writes a dump of every author and article, to stdout when no file
+// is given. A file name ending in .gz is gzipped as well.
+func exportDump(args []string) error {
+	flags := newFlagSet("export", "[file]")
+	gzipped := flags.Bool("gzip", false, "gzip the dump")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+
+		if strings.HasSuffix(path, ".gz") {
+			*gzipped = true
+		}
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	if !*gzipped {
+		return env.app.ExportDump(out)
+	}
+
+	gzipWriter := gzip.NewWriter(out)
+	if err := env.app.ExportDump(gzipWriter); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// restoreDump loads a dump written by export into the configured database,
+// reading stdin when no file is given, and prints what was loaded.
+func restoreDump(args []string) error {
+	flags := newFlagSet("restore", "[file]")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	report, err := env.app.RestoreDump(input)
+	if report != nil {
+		if encodeErr := json.NewEncoder(os.Stdout).Encode(report); encodeErr != nil {
+			fmt.Fprintln(os.Stderr, encodeErr)
+		}
+	}
+	return err
+}
+
+// printJSON prints v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
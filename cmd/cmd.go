@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io"
+	"os"
+	"rest-article/app"
+	"rest-article/config"
+	"rest-article/database"
+	"rest-article/log"
+	"rest-article/repo"
+	"rest-article/search"
+	"strings"
+)
+
+// Version and BuildDate describe the build, they are set through -ldflags
+var (
+	Version   = "dev"
+	BuildDate = "unknown"
+)
+
+// binaryName names the binary in usage messages
+const binaryName = "rest-article"
+
+var logger = log.NewLogger().WithField("package", "cmd")
+
+// errFlags reports a command line the flag package already complained about
+var errFlags = errors.New("bad flags provided")
+
+// usageError reports a command line that does not fit the command, the usage
+// of the command is printed along with it
+type usageError struct {
+	message string
+}
+
+func (err *usageError) Error() string {
+	return err.message
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// command is a subcommand of the binary, it either runs or holds commands of
+// its own
+type command struct {
+	name     string
+	args     string
+	summary  string
+	run      func(args []string) error
+	commands []*command
+}
+
+// commands are the subcommands of the binary, serve runs when none is named
+var commands = []*command{
+	serveCommand,
+	migrateCommand,
+	seedCommand,
+	importCommand,
+	exportCommand,
+	restoreCommand,
+	articleCommand,
+	tagCommand,
+	configCommand,
+	versionCommand,
+}
+
+// Execute runs the subcommand named by args and returns the exit code, 2 for
+// a command line that does not fit.
+func Execute(args []string) int {
+	if len(args) == 0 {
+		args = []string{serveCommand.name}
+	}
+
+	err := execute(commands, nil, args)
+	switch err := err.(type) {
+	case nil:
+		return 0
+	case *usageError:
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	default:
+		if err == errFlags {
+			return 2
+		}
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, "error:", log.Redact(err.Error()))
+		return 1
+	}
+}
+
+func execute(commands []*command, path []string, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stderr, commands, path)
+		if len(args) == 0 {
+			return usagef("no command provided")
+		}
+		return nil
+	}
+
+	for _, command := range commands {
+		if command.name != args[0] {
+			continue
+		}
+		if command.run != nil {
+			return command.run(args[1:])
+		}
+		return execute(command.commands, append(path, command.name), args[1:])
+	}
+
+	printUsage(os.Stderr, commands, path)
+	return usagef("unknown command %s", strings.Join(append(path, args[0]), " "))
+}
+
+func printUsage(w io.Writer, commands []*command, path []string) {
+	prefix := strings.Join(append([]string{binaryName}, path...), " ")
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", prefix)
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-32s %s\n", strings.TrimSpace(command.name+" "+command.args), command.summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", prefix)
+}
+
+// configFlags are the flags every command takes next to -config and -profile,
+// each overrides the setting at its key
+var configFlags = []struct {
+	name  string
+	key   string
+	usage string
+}{
+	{"port", "server.port.http", "port the server listens on"},
+	{"db-type", "database.type", "database driver"},
+	{"db-host", "database.host", "database host"},
+	{"db-port", "database.port", "database port"},
+	{"db-schema", "database.schema", "database schema"},
+	{"db-user", "database.user", "database user"},
+}
+
+// newFlagSet returns the flags of the command along with the config flags,
+// args describes the arguments following the flags
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s [flags] %s\n\nFlags:\n", binaryName, name, args)
+		flags.PrintDefaults()
+	}
+
+	flags.String("config", "", "config file, $"+config.EnvConfig+" or data/config/app.yaml when empty")
+	flags.String("profile", "", "profile layered over the defaults of the config file, $"+config.EnvProfile+" when empty")
+	for _, configFlag := range configFlags {
+		flags.String(configFlag.name, "", configFlag.usage+", overrides "+configFlag.key)
+	}
+	return flags
+}
+
+// parseFlags parses the command line of a command and loads the config it
+// names with the config flags that were set
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errFlags
+	}
+
+	overrides := make(map[string]interface{})
+	for _, configFlag := range configFlags {
+		configFlag := configFlag
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == configFlag.name {
+				overrides[configFlag.key] = f.Value.String()
+			}
+		})
+	}
+
+	err := config.Load(config.Options{
+		Path:      flags.Lookup("config").Value.String(),
+		Profile:   flags.Lookup("profile").Value.String(),
+		Overrides: overrides,
+	})
+	if err != nil {
+		return err
+	}
+
+	applyLogLevel(config.App())
+	return nil
+}
+
+// environment is what the commands working on the database share
+type environment struct {
+	ctx   context.Context
+	db    *sql.DB
+	index search.Index
+	repo  repo.Repo
+	app   *app.App
+	// replicas is nil when no read replica is configured
+	replicas *repo.Replicas
+}
+
+// openEnvironment connects to the configured database
+func openEnvironment() (*environment, error) {
+	ctx := context.Background()
+
+	db, err := database.CreateDatabase()
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %v", err)
+	}
+
+	index := search.NewIndex(ctx, config.App().Database.Type, db)
+
+	replicas, err := openReplicas(ctx)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("replica connection failed: %v", err)
+	}
+
+	baseRepo := repo.NewArticleRepo(ctx, db)
+	if replicas != nil {
+		baseRepo = repo.NewReplicatedArticleRepo(ctx, db, replicas)
+	}
+
+	retry := config.App().Database.Retry
+	articleRepo := repo.NewRetryRepo(ctx, baseRepo, repo.RetryPolicy{
+		Attempts: retry.Attempts,
+		Backoff:  retry.Backoff,
+	})
+
+	return &environment{
+		ctx:      ctx,
+		db:       db,
+		index:    index,
+		repo:     articleRepo,
+		app:      app.NewAppWithRepo(mux.NewRouter(), articleRepo, index, nil, ctx),
+		replicas: replicas,
+	}, nil
+}
+
+// openReplicas opens the configured read replicas, it returns nil when there
+// are none
+func openReplicas(ctx context.Context) (*repo.Replicas, error) {
+	var replicas []repo.Replica
+	for _, replica := range config.App().Database.Replicas {
+		db, err := database.OpenReplica(replica.Host, replica.Port)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.DB.Close()
+			}
+			return nil, err
+		}
+		replicas = append(replicas, repo.Replica{
+			Name: fmt.Sprintf("%s:%d", replica.Host, replica.Port),
+			DB:   db,
+		})
+	}
+
+	if len(replicas) == 0 {
+		return nil, nil
+	}
+	return repo.NewReplicas(ctx, replicas), nil
+}
+
+func (env *environment) Close() {
+	if env.replicas != nil {
+		if err := env.replicas.Close(); err != nil {
+			logger.Errorf("error closing replicas because: %v", err)
+		}
+	}
+	if err := env.db.Close(); err != nil {
+		logger.Errorf("error closing database because: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"rest-article/config"
+)
+
+var configCommand = &command{
+	name:    "config",
+	args:    "print",
+	summary: "inspect the configuration",
+	commands: []*command{
+		{name: "print", summary: "print the configuration in effect, flags applied and secrets redacted", run: printConfig},
+	},
+}
+
+var versionCommand = &command{
+	name:    "version",
+	summary: "print the version of the build",
+	run:     printVersion,
+}
+
+func printConfig(args []string) error {
+	flags := newFlagSet("config print", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(config.RedactedSettings())
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+func printVersion(args []string) error {
+	if len(args) > 0 {
+		return usagef("version takes no arguments")
+	}
+
+	fmt.Printf("%s %s, built %s\n", binaryName, Version, BuildDate)
+	return nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"github.com/golang-migrate/migrate"
+	"github.com/golang-migrate/migrate/source"
+	"io/ioutil"
+	"rest-article/database"
+	"sort"
+	"strconv"
+
+	_ "github.com/golang-migrate/migrate/source/file"
+)
+
+// Default migration directories, setup holds the schema and seed the sample
+// articles
+const (
+	defaultMigrationsPath = "data/db/setup"
+	defaultSeedsPath      = "data/db/seed"
+)
+
+var migrateCommand = &command{
+	name:    "migrate",
+	args:    "up|down|status",
+	summary: "apply, revert or list the schema migrations",
+	commands: []*command{
+		{name: "up", args: "[n]", summary: "apply every pending migration, or the next n", run: migrateUp},
+		{name: "down", args: "n|-all", summary: "revert the last n migrations, or all of them", run: migrateDown},
+		{name: "status", summary: "list the migrations and whether they are applied", run: migrateStatus},
+	},
+}
+
+var seedCommand = &command{
+	name:    "seed",
+	summary: "load the sample articles",
+	run:     seed,
+}
+
+// migrationFlags returns the flags of a migrate command along with the flag
+// naming the migrations directory
+func migrationFlags(name, args string) (*flag.FlagSet, *string) {
+	flags := newFlagSet(name, args)
+	path := flags.String("path", defaultMigrationsPath, "directory holding the migrations")
+	return flags, path
+}
+
+func migrateUp(args []string) error {
+	flags, path := migrationFlags("migrate up", "[n]")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return usagef("migrate up takes at most one argument")
+	}
+
+	steps := 0
+	if flags.NArg() == 1 {
+		n, err := strconv.Atoi(flags.Arg(0))
+		if err != nil || n < 1 {
+			return usagef("number of migrations must be a positive number")
+		}
+		steps = n
+	}
+
+	m, err := database.NewMigrate(*path, database.MigrationsTable)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if steps > 0 {
+		err = m.Steps(steps)
+	} else {
+		err = m.Up()
+	}
+	return reportMigration(m, err)
+}
+
+func migrateDown(args []string) error {
+	flags, path := migrationFlags("migrate down", "n|-all")
+	all := flags.Bool("all", false, "revert every migration")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	// reverting everything by accident is hard to undo, so down either needs a
+	// number or -all
+	steps := 0
+	switch {
+	case *all && flags.NArg() == 0:
+	case !*all && flags.NArg() == 1:
+		n, err := strconv.Atoi(flags.Arg(0))
+		if err != nil || n < 1 {
+			return usagef("number of migrations must be a positive number")
+		}
+		steps = n
+	default:
+		return usagef("migrate down takes either a number of migrations or -all")
+	}
+
+	m, err := database.NewMigrate(*path, database.MigrationsTable)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if steps > 0 {
+		err = m.Steps(-steps)
+	} else {
+		err = m.Down()
+	}
+	return reportMigration(m, err)
+}
+
+func migrateStatus(args []string) error {
+	flags, path := migrationFlags("migrate status", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	migrations, err := readMigrations(*path)
+	if err != nil {
+		return err
+	}
+
+	m, err := database.NewMigrate(*path, database.MigrationsTable)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return err
+	}
+
+	for _, migration := range migrations {
+		status := "pending"
+		if err == nil && migration.Version <= version {
+			status = "applied"
+			if dirty && migration.Version == version {
+				status = "dirty"
+			}
+		}
+		fmt.Printf("%-8d %-40s %s\n", migration.Version, migration.Identifier, status)
+	}
+	return nil
+}
+
+func seed(args []string) error {
+	flags := newFlagSet("seed", "")
+	path := flags.String("path", defaultSeedsPath, "directory holding the seeds")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	m, err := database.NewMigrate(*path, database.SeedsTable)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return reportMigration(m, m.Up())
+}
+
+// reportMigration prints the version the database is at after a migration,
+// there being nothing to migrate is not an error
+func reportMigration(m *migrate.Migrate, err error) error {
+	if err == migrate.ErrNoChange {
+		fmt.Println("no change")
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	switch {
+	case err == migrate.ErrNilVersion:
+		fmt.Println("no migration applied")
+	case err != nil:
+		return err
+	case dirty:
+		fmt.Printf("version %d, dirty\n", version)
+	default:
+		fmt.Printf("version %d\n", version)
+	}
+	return nil
+}
+
+// readMigrations returns the up migrations in the directory at path ordered by
+// version
+func readMigrations(path string) ([]*source.Migration, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []*source.Migration
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		migration, err := source.Parse(file.Name())
+		if err != nil || migration.Direction != source.Up {
+			continue
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"rest-article/app"
+	"rest-article/config"
+	"rest-article/log"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// reloadableSections are the settings applied while the server runs, any other
+// setting only takes effect on restart
+var reloadableSections = []string{"log.", "rate_limit.", "cors.", "compression."}
+
+// reloader applies the config to the running API whenever the config file
+// changes or the process receives SIGHUP
+type reloader struct {
+	mu  sync.Mutex
+	api *app.App
+}
+
+// watchConfig reloads the config on changes to the config file and on SIGHUP
+func watchConfig(api *app.App) {
+	r := &reloader{api: api}
+
+	config.Watch(r.reload)
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			logger.Infof("Received SIGHUP, reloading config")
+			r.reload()
+		}
+	}()
+}
+
+// reload reads the config again and applies the settings that can change
+// while the server runs in one go. A config that does not read or validate is
+// not applied at all.
+func (r *reloader) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, current, err := config.Reload()
+	if err != nil {
+		logger.Errorf("Config reload failed, keeping the running config because: %v", err)
+		return
+	}
+
+	changed := config.Changed(previous, current)
+	if len(changed) == 0 {
+		return
+	}
+	for _, key := range changed {
+		if !reloadable(key) {
+			logger.Warnf("Setting [%s] changed, restart to apply it", key)
+		}
+	}
+
+	applyLogLevel(current)
+
+	runtime := r.api.Runtime()
+	// new limiters would forget the tokens every client has used, so they are
+	// only replaced when their settings change
+	if !reflect.DeepEqual(previous.RateLimit, current.RateLimit) {
+		runtime.RateLimits = newRateLimits()
+	}
+	runtime.CORS = newCORS()
+	runtime.Compression = newCompression()
+	r.api.SetRuntime(runtime)
+
+	logger.Infof("Config reloaded, %d settings changed", len(changed))
+}
+
+func reloadable(key string) bool {
+	for _, section := range reloadableSections {
+		if strings.HasPrefix(key, section) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyLogLevel sets the level of every logger from the config
+func applyLogLevel(appConfig *config.AppConfig) {
+	level, err := appConfig.LogLevel()
+	if err != nil {
+		logger.Errorf("error setting log level because: %v", err)
+		return
+	}
+	log.SetLevel(level)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"rest-article/app"
+	"rest-article/auth"
+	"rest-article/config"
+	"rest-article/job"
+	"rest-article/ratelimit"
+	"rest-article/search"
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	summary: "run the API along with its background jobs, the default",
+	run:     serve,
+}
+
+// serve runs the API along with its background jobs
+func serve(args []string) error {
+	flags := newFlagSet("serve", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("serve takes no arguments")
+	}
+
+	logger.Infof("Starting server on port ['%d']", config.App().Server.Port.Http)
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	authenticator, err := newAuthenticator(env.repo)
+	if err != nil {
+		return fmt.Errorf("authentication setup failed: %v", err)
+	}
+
+	api := app.NewAppWithRepo(
+		mux.NewRouter().StrictSlash(true),
+		env.repo,
+		env.index,
+		authenticator,
+		env.ctx)
+
+	api.SetRuntime(app.Runtime{
+		RateLimits:  newRateLimits(),
+		CORS:        newCORS(),
+		Compression: newCompression(),
+	})
+	if env.replicas != nil {
+		logger.Infof("Reading from %d replicas", len(config.App().Database.Replicas))
+		go env.replicas.Run(config.App().Database.ReplicaCheck.Interval)
+
+		if rw := config.App().Database.ReadYourWrites; rw.Enabled {
+			api.SetReadYourWrites(app.NewReadYourWrites(rw.Window, config.App().RateLimit.TrustForwardedFor))
+		}
+	}
+	api.SetupRouter()
+	watchConfig(api)
+
+	// the in process index starts empty on every run
+	if _, ok := env.index.(*search.MemoryIndex); ok {
+		count, err := api.SeedIndex()
+		if err != nil {
+			return fmt.Errorf("search index setup failed: %v", err)
+		}
+		logger.Infof("Indexed %d articles for search", count)
+	}
+
+	scheduler := job.NewScheduler(env.ctx, env.repo, env.index, config.App().Scheduler.Interval)
+	go scheduler.Run()
+
+	purger := job.NewPurger(env.ctx,
+		env.repo,
+		config.App().Purge.Retention,
+		config.App().Purge.Interval)
+	go purger.Run()
+
+	if err := http.ListenAndServe(fmt.Sprintf("0.0.0.0:%d", config.App().Server.Port.Http), api.Router); err != nil {
+		return fmt.Errorf("error starting server because: %v", err)
+	}
+	return nil
+}
+
+// newAuthenticator builds the authenticator from the auth config, it returns nil
+// when authentication is disabled.
+func newAuthenticator(store auth.KeyStore) (*auth.Authenticator, error) {
+	authConfig := config.App().Auth
+	if !authConfig.Enabled {
+		logger.Warnf("Authentication is disabled, every route is open")
+		return nil, nil
+	}
+
+	var staticKeys []auth.StaticKey
+	for _, key := range authConfig.APIKeys {
+		staticKeys = append(staticKeys, auth.StaticKey{
+			Name:     key.Name,
+			Hash:     key.Hash,
+			Role:     key.Role,
+			AuthorId: key.AuthorId,
+			Scopes:   key.Scopes,
+		})
+	}
+
+	var verifier *auth.JWTVerifier
+	if authConfig.JWT.HS256Secret != "" || authConfig.JWT.RS256PublicKeyFile != "" {
+		var publicKey []byte
+		if authConfig.JWT.RS256PublicKeyFile != "" {
+			data, err := ioutil.ReadFile(authConfig.JWT.RS256PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			publicKey = data
+		}
+
+		jwtVerifier, err := auth.NewJWTVerifier(authConfig.JWT.HS256Secret, publicKey,
+			authConfig.JWT.Issuer, authConfig.JWT.Audience)
+		if err != nil {
+			return nil, err
+		}
+		verifier = jwtVerifier
+	}
+
+	return auth.NewAuthenticator(staticKeys, store, verifier), nil
+}
+
+// newRateLimits builds the rate limits from the rate limit config, it returns
+// nil when rate limiting is disabled.
+func newRateLimits() *app.RateLimits {
+	limitConfig := config.App().RateLimit
+	if !limitConfig.Enabled {
+		return nil
+	}
+
+	limits := &app.RateLimits{
+		TrustForwardedFor: limitConfig.TrustForwardedFor,
+	}
+	if limitConfig.Read.Rate > 0 {
+		limits.Read = ratelimit.NewLimiter(limitConfig.Read.Rate, limitConfig.Read.Burst)
+	}
+	if limitConfig.Write.Rate > 0 {
+		limits.Write = ratelimit.NewLimiter(limitConfig.Write.Rate, limitConfig.Write.Burst)
+	}
+	if limitConfig.DailyQuota > 0 {
+		limits.Quota = ratelimit.NewQuota(limitConfig.DailyQuota)
+	}
+
+	return limits
+}
+
+// newCORS builds the cross origin policy from the cors config, it returns nil
+// when no origins are allowed.
+func newCORS() *app.CORS {
+	corsConfig := config.App().CORS
+	if len(corsConfig.AllowedOrigins) == 0 {
+		return nil
+	}
+
+	return &app.CORS{
+		AllowedOrigins:   corsConfig.AllowedOrigins,
+		AllowedMethods:   corsConfig.AllowedMethods,
+		AllowedHeaders:   corsConfig.AllowedHeaders,
+		ExposedHeaders:   corsConfig.ExposedHeaders,
+		AllowCredentials: corsConfig.AllowCredentials,
+		MaxAge:           corsConfig.MaxAge,
+	}
+}
+
+// newCompression builds the response compression from the compression config,
+// it returns nil when compression is disabled.
+func newCompression() *app.Compression {
+	compressionConfig := config.App().Compression
+	if !compressionConfig.Enabled {
+		return nil
+	}
+
+	return &app.Compression{
+		MinSize:      compressionConfig.MinSize,
+		ContentTypes: compressionConfig.ContentTypes,
+		Level:        compressionConfig.Level,
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+var tagCommand = &command{
+	name:    "tag",
+	args:    "list|merge",
+	summary: "list or merge tags",
+	commands: []*command{
+		{name: "list", summary: "list the tags with the number of articles holding them", run: listTags},
+		{name: "merge", args: "<from> <into>", summary: "move the articles of a tag onto another and delete it", run: mergeTags},
+	},
+}
+
+func listTags(args []string) error {
+	flags := newFlagSet("tag list", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	tags, err := env.repo.GetTagCounts()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tARTICLES")
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%d\t%s\t%d\n", tag.Id, tag.Name, tag.Count)
+	}
+	return w.Flush()
+}
+
+func mergeTags(args []string) error {
+	flags := newFlagSet("tag merge", "<from> <into>")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usagef("tag merge takes the tag to merge and the tag to merge it into")
+	}
+	from, into := flags.Arg(0), flags.Arg(1)
+	if from == into {
+		return usagef("a tag can not be merged into itself")
+	}
+
+	env, err := openEnvironment()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	moved, err := env.repo.MergeTags(from, into)
+	if err == sql.ErrNoRows {
+		return errors.New("tag not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("merged %s into %s, %d articles moved\n", from, into, moved)
+	return nil
+}
@@ -1,6 +1,11 @@
 package config
 
+import "time"
+
 type AppConfig struct {
+	Log struct {
+		Level string `mapstructure:"level"`
+	} `mapstructure:"log"`
 	Server struct {
 		Host string `mapstructure:"host"`
 		Port struct {
@@ -14,5 +19,94 @@ type AppConfig struct {
 		Host   string `mapstructure:"host"`
 		User   string `mapstructure:"user"`
 		Pass   string `mapstructure:"pass"`
+		// PassFile names a file holding the password, read in place of Pass
+		PassFile string `mapstructure:"pass_file"`
+		Pool     struct {
+			MaxOpen         int           `mapstructure:"max_open"`
+			MaxIdle         int           `mapstructure:"max_idle"`
+			ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+			ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+		} `mapstructure:"pool"`
+		// Connect is how long startup waits for the database to answer, the
+		// wait between pings starts at Backoff and doubles up to MaxBackoff
+		Connect struct {
+			Timeout    time.Duration `mapstructure:"timeout"`
+			Backoff    time.Duration `mapstructure:"backoff"`
+			MaxBackoff time.Duration `mapstructure:"max_backoff"`
+		} `mapstructure:"connect"`
+		// Retry is how idempotent operations failing with a transient error,
+		// such as a deadlock, are retried
+		Retry struct {
+			Attempts int           `mapstructure:"attempts"`
+			Backoff  time.Duration `mapstructure:"backoff"`
+		} `mapstructure:"retry"`
+		// Replicas serve articles by id and the tag aggregates in turn, with
+		// the schema, user and password of the primary
+		Replicas []struct {
+			Host string `mapstructure:"host"`
+			Port int    `mapstructure:"port"`
+		} `mapstructure:"replicas"`
+		// ReplicaCheck is how often replicas are pinged, one that does not
+		// answer gets no reads until it does
+		ReplicaCheck struct {
+			Interval time.Duration `mapstructure:"interval"`
+		} `mapstructure:"replica_check"`
+		// ReadYourWrites pins a client to the primary for Window after it
+		// wrote, so it reads back its writes while the replicas catch up
+		ReadYourWrites struct {
+			Enabled bool          `mapstructure:"enabled"`
+			Window  time.Duration `mapstructure:"window"`
+		} `mapstructure:"read_your_writes"`
+	}
+	Auth struct {
+		Enabled bool `mapstructure:"enabled"`
+		APIKeys []struct {
+			Name     string   `mapstructure:"name"`
+			Hash     string   `mapstructure:"hash"`
+			Role     string   `mapstructure:"role"`
+			AuthorId int      `mapstructure:"author_id"`
+			Scopes   []string `mapstructure:"scopes"`
+		} `mapstructure:"api_keys"`
+		JWT struct {
+			HS256Secret        string `mapstructure:"hs256_secret"`
+			HS256SecretFile    string `mapstructure:"hs256_secret_file"`
+			RS256PublicKeyFile string `mapstructure:"rs256_public_key_file"`
+			Issuer             string `mapstructure:"issuer"`
+			Audience           string `mapstructure:"audience"`
+		} `mapstructure:"jwt"`
+	} `mapstructure:"auth"`
+	RateLimit struct {
+		Enabled           bool `mapstructure:"enabled"`
+		TrustForwardedFor bool `mapstructure:"trust_forwarded_for"`
+		Read              struct {
+			Rate  float64 `mapstructure:"rate"`
+			Burst int     `mapstructure:"burst"`
+		} `mapstructure:"read"`
+		Write struct {
+			Rate  float64 `mapstructure:"rate"`
+			Burst int     `mapstructure:"burst"`
+		} `mapstructure:"write"`
+		DailyQuota int `mapstructure:"daily_quota"`
+	} `mapstructure:"rate_limit"`
+	CORS struct {
+		AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+		AllowedMethods   []string      `mapstructure:"allowed_methods"`
+		AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+		ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+		AllowCredentials bool          `mapstructure:"allow_credentials"`
+		MaxAge           time.Duration `mapstructure:"max_age"`
+	} `mapstructure:"cors"`
+	Compression struct {
+		Enabled      bool     `mapstructure:"enabled"`
+		MinSize      int      `mapstructure:"min_size"`
+		Level        int      `mapstructure:"level"`
+		ContentTypes []string `mapstructure:"content_types"`
+	} `mapstructure:"compression"`
+	Scheduler struct {
+		Interval time.Duration `mapstructure:"interval"`
+	}
+	Purge struct {
+		Retention time.Duration `mapstructure:"retention"`
+		Interval  time.Duration `mapstructure:"interval"`
 	}
 }
@@ -1,52 +1,350 @@
 package config
 
 import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"os"
+	"reflect"
 	"rest-article/log"
+	"sort"
+	"strings"
+	"sync"
 )
 
 const (
 	appConfigPath = "data/config/app.yaml"
+
+	// defaultsProfile holds the settings every profile is layered over
+	defaultsProfile = "defaults"
+
+	// EnvPrefix starts the environment variables overriding settings, the
+	// setting database.pass is overridden by APP_DATABASE_PASS
+	EnvPrefix = "APP"
+
+	// EnvConfig and EnvProfile name the config file and the profile when no
+	// flag does
+	EnvConfig  = EnvPrefix + "_CONFIG"
+	EnvProfile = EnvPrefix + "_PROFILE"
 )
 
 var (
-	logger    = log.NewLogger().WithField("module", "config")
-	configApp *AppConfig
+	logger = log.NewLogger().WithField("module", "config")
+
+	// configMu guards the loaded config, which is replaced on reload
+	configMu       sync.RWMutex
+	configApp      *AppConfig
+	configSettings map[string]interface{}
+	configOptions  Options
 )
 
-func init() {
-	configApp = loadAppConfig()
+// Options picks the config file and profile to load and the settings to
+// override
+type Options struct {
+	// Path is the config file, $APP_CONFIG or data/config/app.yaml when empty
+	Path string
+	// Profile is layered over the defaults of the config file, $APP_PROFILE
+	// when empty and the defaults alone when both are empty
+	Profile string
+	// Overrides are keyed by the dotted path of a setting, such as
+	// database.host, and take precedence over the environment
+	Overrides map[string]interface{}
 }
 
+// App returns the loaded app config. Load has to be called first, calling App
+// before it is a programming error and panics.
 func App() *AppConfig {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	mustBeLoaded("App")
+	return configApp
+}
+
+// mustBeLoaded panics when Load has not been called yet, the caller holds
+// configMu
+func mustBeLoaded(caller string) {
 	if configApp == nil {
-		configApp = loadAppConfig()
+		panic(fmt.Sprintf("config: %s called before Load", caller))
 	}
-	return configApp
 }
 
-func loadAppConfig() *AppConfig {
-	configMap := map[string]*AppConfig{}
+// Load reads the app config. The settings of the profile are layered over the
+// defaults, environment variables over both and the overrides over everything.
+// The config is only replaced when it reads and validates.
+func Load(options Options) error {
+	options = resolveOptions(options)
 
-	_, err := readConfigFromFile("app", appConfigPath, &configMap)
+	app, settings, err := readAppConfig(options)
 	if err != nil {
-		logger.Fatalf("Unable to read app config with error %v", err)
+		return err
 	}
 
-	app, ok := configMap["defaults"]
-	if app == nil || !ok {
-		logger.Fatalf("unable to get environment [defaults] in config [%s] with error: %v", appConfigPath, err)
+	configMu.Lock()
+	defer configMu.Unlock()
+	configApp = app
+	configSettings = settings
+	configOptions = options
+	return nil
+}
+
+// Reload reads the app config again with the options of the last Load and
+// returns the config it replaced along with the new one. The config is kept
+// when it no longer reads or validates.
+func Reload() (previous, current *AppConfig, err error) {
+	previous = App()
+
+	configMu.RLock()
+	options := configOptions
+	configMu.RUnlock()
+
+	if err := Load(options); err != nil {
+		return previous, previous, err
 	}
+	return previous, App(), nil
+}
+
+// Watch calls onChange whenever the config file of the last Load is written,
+// for as long as the process runs
+func Watch(onChange func()) {
+	configMu.RLock()
+	mustBeLoaded("Watch")
+	path := configOptions.Path
+	configMu.RUnlock()
+
+	vip := viper.New()
+	vip.SetConfigType("yaml")
+	vip.SetConfigFile(path)
+	vip.OnConfigChange(func(event fsnotify.Event) {
+		logger.Infof("Config [%s] changed", event.Name)
+		onChange()
+	})
+	vip.WatchConfig()
+}
+
+// Settings returns the settings of the app config keyed as in app.yaml, secrets
+// included
+func Settings() map[string]interface{} {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	mustBeLoaded("Settings")
+	return configSettings
+}
 
-	return app
+// RedactedSettings returns the settings of the app config keyed as in app.yaml
+// with the secrets replaced, for display
+func RedactedSettings() map[string]interface{} {
+	return redactSettings(Settings())
 }
 
-func readConfigFromFile(filename, path string, target interface{}) (*viper.Viper, error) {
+// EnvName returns the environment variable overriding the setting at key
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// resolveOptions fills in the config file and profile from the environment, so
+// a reload reads the same ones
+func resolveOptions(options Options) Options {
+	if options.Path == "" {
+		options.Path = os.Getenv(EnvConfig)
+	}
+	if options.Path == "" {
+		options.Path = appConfigPath
+	}
+	if options.Profile == "" {
+		options.Profile = os.Getenv(EnvProfile)
+	}
+	return options
+}
+
+func readAppConfig(options Options) (*AppConfig, map[string]interface{}, error) {
+	options = resolveOptions(options)
+	path, profile := options.Path, options.Profile
+
+	file, err := readConfigFromFile("app", path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := profileSettings(file, path, profile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vip := viper.New()
+	if err := vip.MergeConfigMap(settings); err != nil {
+		return nil, nil, err
+	}
+	for _, key := range configKeys(reflect.TypeOf(AppConfig{}), "") {
+		value, ok := os.LookupEnv(EnvName(key.name))
+		if !ok {
+			continue
+		}
+		if key.list {
+			vip.Set(key.name, splitList(value))
+			continue
+		}
+		vip.Set(key.name, value)
+	}
+	for key, value := range options.Overrides {
+		vip.Set(key, value)
+	}
+	if err := readSecretFiles(vip); err != nil {
+		return nil, nil, err
+	}
+
+	app := &AppConfig{}
+	if err := vip.Unmarshal(app); err != nil {
+		logger.WithFields(log.ErrorFields("config", "Unmarshal")).
+			Errorf("Unable to Unmarshall config [app] with error: %v", err)
+		return nil, nil, err
+	}
+
+	if err := app.Validate(); err != nil {
+		return nil, nil, err
+	}
+	addSecrets(vip)
+
+	return app, vip.AllSettings(), nil
+}
+
+// profileSettings returns the defaults of the config file with the settings of
+// the profile merged over them
+func profileSettings(file *viper.Viper, path, profile string) (map[string]interface{}, error) {
+	all := file.AllSettings()
+
+	defaults, ok := all[defaultsProfile].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unable to get environment [%s] in config [%s]", defaultsProfile, path)
+	}
+	if profile == "" || profile == defaultsProfile {
+		return defaults, nil
+	}
+
+	// AllSettings leaves out a profile without settings, InConfig does not
+	if !file.InConfig(profile) {
+		var profiles []string
+		for _, name := range file.AllKeys() {
+			name = strings.Split(name, ".")[0]
+			if name != defaultsProfile && !contains(profiles, name) {
+				profiles = append(profiles, name)
+			}
+		}
+		sort.Strings(profiles)
+		return nil, fmt.Errorf("unknown profile [%s] in config [%s], expected one of %s",
+			profile, path, strings.Join(profiles, ", "))
+	}
+
+	overlay := all[profile]
+	if overlay == nil {
+		return defaults, nil
+	}
+	overlayMap, ok := overlay.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("profile [%s] in config [%s] is not a map of settings", profile, path)
+	}
+
+	return mergeSettings(defaults, overlayMap), nil
+}
+
+// mergeSettings returns base with the settings of overlay merged over it,
+// sections are merged setting by setting and anything else, lists included,
+// is replaced
+func mergeSettings(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		baseSection, baseOk := merged[key].(map[string]interface{})
+		section, ok := value.(map[string]interface{})
+		if baseOk && ok {
+			merged[key] = mergeSettings(baseSection, section)
+			continue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// Changed lists the dotted keys of the settings that differ between previous
+// and current, lists are compared as a whole
+func Changed(previous, current *AppConfig) []string {
+	return changedKeys(reflect.ValueOf(*previous), reflect.ValueOf(*current), "")
+}
+
+func changedKeys(previous, current reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < previous.NumField(); i++ {
+		field := previous.Type().Field(i)
+		name := prefix + fieldName(field)
+
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, changedKeys(previous.Field(i), current.Field(i), name+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(previous.Field(i).Interface(), current.Field(i).Interface()) {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// fieldName returns the key of a setting within its section
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// configKey is a setting that can be given as a single value, lists are
+// given comma separated
+type configKey struct {
+	name string
+	list bool
+}
+
+// configKeys lists the settings of t, lists of sections such as auth.api_keys
+// can not be given as a single value and are left out
+func configKeys(t reflect.Type, prefix string) []configKey {
+	var keys []configKey
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := prefix + fieldName(field)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(field.Type, name+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+		default:
+			keys = append(keys, configKey{name: name, list: field.Type.Kind() == reflect.Slice})
+		}
+	}
+	return keys
+}
+
+// splitList splits a comma separated list, an empty value is an empty list
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func readConfigFromFile(filename, path string) (*viper.Viper, error) {
 	vip := viper.New()
 
 	vip.SetConfigType("yaml")
 	vip.SetConfigFile(path)
-	vip.AutomaticEnv()
 
 	err := vip.ReadInConfig()
 	if err != nil {
@@ -55,12 +353,5 @@ func readConfigFromFile(filename, path string, target interface{}) (*viper.Viper
 		return nil, err
 	}
 
-	err = vip.Unmarshal(&target)
-	if err != nil {
-		logger.WithFields(log.ErrorFields("config", "Unmarshal")).
-			Errorf("Unable to Unmarshall config [%s] with error: %v", filename, err)
-		return nil, err
-	}
-
 	return vip, nil
 }
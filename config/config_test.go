@@ -0,0 +1,238 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"rest-article/log"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+defaults:
+  log:
+    level: "info"
+  server:
+    port:
+      http: 8080
+  database:
+    type: "mysql"
+    port: 3306
+    schema: "svc-article"
+    host: "localhost"
+    user: "root"
+    pass: "root"
+  cors:
+    allowed_origins: ["https://example.com"]
+  scheduler:
+    interval: "1m"
+  purge:
+    retention: "720h"
+    interval: "1h"
+
+dev:
+
+prod:
+  database:
+    host: "mysql"
+  cors:
+    allowed_origins: []
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "app.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestReadAppConfigProfiles(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+
+	app, _, err := readAppConfig(Options{Path: path})
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", app.Database.Host)
+	assert.Equal(t, []string{"https://example.com"}, app.CORS.AllowedOrigins)
+
+	app, _, err = readAppConfig(Options{Path: path, Profile: "prod"})
+	assert.Nil(t, err)
+	assert.Equal(t, "mysql", app.Database.Host)
+	assert.Equal(t, "root", app.Database.User)
+	assert.Empty(t, app.CORS.AllowedOrigins)
+	assert.Equal(t, time.Minute, app.Scheduler.Interval)
+
+	app, _, err = readAppConfig(Options{Path: path, Profile: "dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost", app.Database.Host)
+
+	_, _, err = readAppConfig(Options{Path: path, Profile: "qa"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown profile [qa]")
+}
+
+func TestReadAppConfigOverrides(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+
+	os.Setenv(EnvProfile, "prod")
+	os.Setenv("APP_DATABASE_PASS", "from-env")
+	os.Setenv("APP_DATABASE_HOST", "env-host")
+	os.Setenv("APP_CORS_ALLOWED_ORIGINS", "https://a.com, https://b.com")
+	defer func() {
+		os.Unsetenv(EnvProfile)
+		os.Unsetenv("APP_DATABASE_PASS")
+		os.Unsetenv("APP_DATABASE_HOST")
+		os.Unsetenv("APP_CORS_ALLOWED_ORIGINS")
+	}()
+
+	app, settings, err := readAppConfig(Options{
+		Path:      path,
+		Overrides: map[string]interface{}{"database.host": "flag-host", "server.port.http": "9090"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", app.Database.Pass)
+	assert.Equal(t, "flag-host", app.Database.Host)
+	assert.Equal(t, 9090, app.Server.Port.Http)
+	assert.Equal(t, []string{"https://a.com", "https://b.com"}, app.CORS.AllowedOrigins)
+	assert.Equal(t, "from-env", settings["database"].(map[string]interface{})["pass"])
+}
+
+func TestReadAppConfigValidation(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+
+	os.Setenv("APP_DATABASE_TYPE", "postgres")
+	defer os.Unsetenv("APP_DATABASE_TYPE")
+
+	_, _, err := readAppConfig(Options{
+		Path:      path,
+		Overrides: map[string]interface{}{"server.port.http": 0, "compression.level": 12},
+	})
+
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{
+		"server.port.http must be between 1 and 65535, got 0",
+		`database.type must be one of mysql, got "postgres"`,
+		"compression.level must be between 0 and 9, got 12",
+	}, validationErr.Problems)
+}
+
+func TestValidateAPIKeys(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	app, _, err := readAppConfig(Options{Path: path})
+	assert.Nil(t, err)
+
+	app.Auth.Enabled = true
+	app.Auth.APIKeys = append(app.Auth.APIKeys, struct {
+		Name     string   `mapstructure:"name"`
+		Hash     string   `mapstructure:"hash"`
+		Role     string   `mapstructure:"role"`
+		AuthorId int      `mapstructure:"author_id"`
+		Scopes   []string `mapstructure:"scopes"`
+	}{Name: "writer", Hash: "abc", Role: "author"})
+
+	err = app.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid config: auth.api_keys[0].hash must be a hex encoded sha256 hash; "+
+		"auth.api_keys[0].author_id is required for the author role", err.Error())
+}
+
+func TestValidateCORSWildcardWithCredentials(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	app, _, err := readAppConfig(Options{Path: path})
+	assert.Nil(t, err)
+
+	app.CORS.AllowedOrigins = []string{"*"}
+	app.CORS.AllowCredentials = true
+
+	err = app.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, `invalid config: cors.allow_credentials needs allowed_origins listed by name, not "*"`, err.Error())
+}
+
+func TestValidatePurge(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	app, _, err := readAppConfig(Options{Path: path})
+	assert.Nil(t, err)
+
+	app.Purge.Interval = 0
+	app.Purge.Retention = 0
+	assert.Nil(t, app.Validate())
+
+	app.Purge.Interval = -time.Hour
+	err = app.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid config: purge.interval must not be negative", err.Error())
+}
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	for _, profile := range []string{"", "dev", "staging", "prod"} {
+		_, _, err := readAppConfig(Options{Path: filepath.Join("..", appConfigPath), Profile: profile})
+		assert.Nil(t, err, profile)
+	}
+}
+
+func TestAppBeforeLoad(t *testing.T) {
+	configMu.Lock()
+	loaded := configApp
+	configApp = nil
+	configMu.Unlock()
+	defer func() {
+		configMu.Lock()
+		configApp = loaded
+		configMu.Unlock()
+	}()
+
+	assert.PanicsWithValue(t, "config: App called before Load", func() { App() })
+	assert.PanicsWithValue(t, "config: Settings called before Load", func() { Settings() })
+}
+
+func TestReadAppConfigSecretFile(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	secret := filepath.Join(filepath.Dir(path), "db-pass")
+	assert.Nil(t, ioutil.WriteFile(secret, []byte("from-file\n"), 0600))
+
+	app, settings, err := readAppConfig(Options{
+		Path:      path,
+		Overrides: map[string]interface{}{"database.pass_file": secret},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "from-file", app.Database.Pass)
+
+	redacted := redactSettings(settings)
+	assert.Equal(t, log.Redacted, redacted["database"].(map[string]interface{})["pass"])
+	assert.Equal(t, "from-file", settings["database"].(map[string]interface{})["pass"])
+	assert.Equal(t, log.Redacted+"@tcp", log.Redact("from-file@tcp"))
+
+	_, _, err = readAppConfig(Options{
+		Path:      path,
+		Overrides: map[string]interface{}{"database.pass_file": secret + "-missing"},
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to read database.pass_file")
+}
+
+func TestChanged(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	previous, _, err := readAppConfig(Options{Path: path})
+	assert.Nil(t, err)
+
+	current, _, err := readAppConfig(Options{
+		Path: path,
+		Overrides: map[string]interface{}{
+			"server.port.http":     "9090",
+			"log.level":            "debug",
+			"cors.allowed_origins": []string{"https://a.com"},
+		},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"log.level", "server.port.http", "cors.allowed_origins"}, Changed(previous, current))
+	assert.Empty(t, Changed(previous, previous))
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"io/ioutil"
+	"rest-article/log"
+	"strings"
+)
+
+// secretFileSuffix ends the key naming the file a secret is read from, such as
+// database.pass_file for database.pass
+const secretFileSuffix = "_file"
+
+// secretKeys are the settings holding secrets, they are redacted from the logs
+// and from RedactedSettings
+var secretKeys = []string{
+	"database.pass",
+	"auth.jwt.hs256_secret",
+}
+
+// readSecretFiles reads the secrets whose _file key is set from their files,
+// which take the place of the secret set directly. Trailing line breaks are
+// dropped as mounted secrets often end in one.
+func readSecretFiles(vip *viper.Viper) error {
+	for _, key := range secretKeys {
+		path := vip.GetString(key + secretFileSuffix)
+		if path == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			logger.WithFields(log.ErrorFields("config", "ReadSecret")).
+				Errorf("Unable to read secret [%s] with error %v", key, err)
+			return fmt.Errorf("unable to read %s%s: %v", key, secretFileSuffix, err)
+		}
+
+		vip.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
+
+// addSecrets registers the secrets with the log package so no log entry
+// carries them
+func addSecrets(vip *viper.Viper) {
+	for _, key := range secretKeys {
+		log.AddSecret(vip.GetString(key))
+	}
+}
+
+// redactSettings returns a copy of settings with every secret that is set
+// replaced
+func redactSettings(settings map[string]interface{}) map[string]interface{} {
+	redacted := copySettings(settings)
+
+	for _, key := range secretKeys {
+		path := strings.Split(key, ".")
+		section := redacted
+		for _, name := range path[:len(path)-1] {
+			next, ok := section[name].(map[string]interface{})
+			if !ok {
+				section = nil
+				break
+			}
+			section = next
+		}
+
+		name := path[len(path)-1]
+		if section != nil && section[name] != nil && section[name] != "" {
+			section[name] = log.Redacted
+		}
+	}
+
+	return redacted
+}
+
+// copySettings copies settings section by section, so the copy can be changed
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		if section, ok := value.(map[string]interface{}); ok {
+			value = copySettings(section)
+		}
+		copied[key] = value
+	}
+	return copied
+}
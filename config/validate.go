@@ -0,0 +1,193 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"rest-article/auth"
+	"strings"
+)
+
+// databaseTypes are the database drivers the service is built with
+var databaseTypes = []string{"mysql"}
+
+var roles = []string{auth.RoleReader, auth.RoleAuthor, auth.RoleEditor, auth.RoleAdmin}
+
+// ValidationError lists every problem found with a config, each naming the
+// setting at fault
+type ValidationError struct {
+	Problems []string
+}
+
+func (err *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(err.Problems, "; ")
+}
+
+// LogLevel returns the configured log level, info when none is
+func (app *AppConfig) LogLevel() (logrus.Level, error) {
+	if app.Log.Level == "" {
+		return logrus.InfoLevel, nil
+	}
+	return logrus.ParseLevel(app.Log.Level)
+}
+
+// Validate checks the settings the service can not run without or can not
+// make sense of, it returns a *ValidationError listing all of them.
+func (app *AppConfig) Validate() error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if _, err := app.LogLevel(); err != nil {
+		problem("log.level must be one of %s, got %q", strings.Join(logLevels(), ", "), app.Log.Level)
+	}
+
+	if !validPort(app.Server.Port.Http) {
+		problem("server.port.http must be between 1 and 65535, got %d", app.Server.Port.Http)
+	}
+
+	database := app.Database
+	if !contains(databaseTypes, database.Type) {
+		problem("database.type must be one of %s, got %q", strings.Join(databaseTypes, ", "), database.Type)
+	}
+	if database.Host == "" {
+		problem("database.host is required")
+	}
+	if !validPort(database.Port) {
+		problem("database.port must be between 1 and 65535, got %d", database.Port)
+	}
+	if database.Schema == "" {
+		problem("database.schema is required")
+	}
+	if database.User == "" {
+		problem("database.user is required")
+	}
+	for _, setting := range []struct {
+		name  string
+		value int64
+	}{
+		{"database.pool.max_open", int64(database.Pool.MaxOpen)},
+		{"database.pool.max_idle", int64(database.Pool.MaxIdle)},
+		{"database.pool.conn_max_lifetime", int64(database.Pool.ConnMaxLifetime)},
+		{"database.pool.conn_max_idle_time", int64(database.Pool.ConnMaxIdleTime)},
+		{"database.connect.timeout", int64(database.Connect.Timeout)},
+		{"database.connect.backoff", int64(database.Connect.Backoff)},
+		{"database.connect.max_backoff", int64(database.Connect.MaxBackoff)},
+		{"database.retry.attempts", int64(database.Retry.Attempts)},
+		{"database.retry.backoff", int64(database.Retry.Backoff)},
+	} {
+		if setting.value < 0 {
+			problem("%s must not be negative", setting.name)
+		}
+	}
+	if database.Pool.MaxOpen > 0 && database.Pool.MaxIdle > database.Pool.MaxOpen {
+		problem("database.pool.max_idle must not be above database.pool.max_open")
+	}
+	if database.Connect.Timeout > 0 && database.Connect.Backoff <= 0 {
+		problem("database.connect.backoff must be positive when a connect timeout is set")
+	}
+	for i, replica := range database.Replicas {
+		if replica.Host == "" {
+			problem("database.replicas[%d].host is required", i)
+		}
+		if !validPort(replica.Port) {
+			problem("database.replicas[%d].port must be between 1 and 65535, got %d", i, replica.Port)
+		}
+	}
+	if len(database.Replicas) > 0 && database.ReplicaCheck.Interval <= 0 {
+		problem("database.replica_check.interval must be positive when replicas are set")
+	}
+	if database.ReadYourWrites.Enabled && database.ReadYourWrites.Window <= 0 {
+		problem("database.read_your_writes.window must be positive when read your writes is enabled")
+	}
+
+	if app.Auth.Enabled {
+		for i, key := range app.Auth.APIKeys {
+			name := fmt.Sprintf("auth.api_keys[%d]", i)
+			if key.Name == "" {
+				problem("%s.name is required", name)
+			}
+			if hash, err := hex.DecodeString(key.Hash); err != nil || len(hash) != 32 {
+				problem("%s.hash must be a hex encoded sha256 hash", name)
+			}
+			if !contains(roles, key.Role) {
+				problem("%s.role must be one of %s, got %q", name, strings.Join(roles, ", "), key.Role)
+			}
+			if key.Role == auth.RoleAuthor && key.AuthorId <= 0 {
+				problem("%s.author_id is required for the author role", name)
+			}
+		}
+	}
+
+	limits := app.RateLimit
+	for _, limit := range []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"rate_limit.read", limits.Read.Rate, limits.Read.Burst},
+		{"rate_limit.write", limits.Write.Rate, limits.Write.Burst},
+	} {
+		if limit.rate < 0 {
+			problem("%s.rate must not be negative", limit.name)
+		}
+		if limit.rate > 0 && limit.burst < 1 {
+			problem("%s.burst must be at least 1 when a rate is set", limit.name)
+		}
+	}
+	if limits.DailyQuota < 0 {
+		problem("rate_limit.daily_quota must not be negative")
+	}
+
+	if app.CORS.AllowCredentials && contains(app.CORS.AllowedOrigins, "*") {
+		problem("cors.allow_credentials needs allowed_origins listed by name, not \"*\"")
+	}
+	if app.CORS.MaxAge < 0 {
+		problem("cors.max_age must not be negative")
+	}
+
+	if app.Compression.Level < 0 || app.Compression.Level > 9 {
+		problem("compression.level must be between 0 and 9, got %d", app.Compression.Level)
+	}
+	if app.Compression.MinSize < 0 {
+		problem("compression.min_size must not be negative")
+	}
+
+	if app.Scheduler.Interval <= 0 {
+		problem("scheduler.interval must be positive")
+	}
+	// a zero purge interval or retention disables the purge job
+	if app.Purge.Interval < 0 {
+		problem("purge.interval must not be negative")
+	}
+	if app.Purge.Retention < 0 {
+		problem("purge.retention must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func logLevels() []string {
+	var levels []string
+	for _, level := range logrus.AllLevels {
+		levels = append(levels, level.String())
+	}
+	return levels
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
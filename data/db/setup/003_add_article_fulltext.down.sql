@@ -0,0 +1,2 @@
+ALTER TABLE `svc-article`.articles
+    DROP INDEX `FT_TITLE_BODY`;
@@ -0,0 +1,2 @@
+ALTER TABLE `svc-article`.articles
+    ADD FULLTEXT INDEX `FT_TITLE_BODY` (title, body);
@@ -0,0 +1 @@
+DROP TABLE `svc-article`.article_revisions;
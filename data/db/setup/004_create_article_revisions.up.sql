@@ -0,0 +1,30 @@
+CREATE TABLE `svc-article`.article_revisions
+(
+    id         INT UNSIGNED AUTO_INCREMENT,
+    article_id INT UNSIGNED NOT NULL,
+    revision   INT UNSIGNED NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    date       DATE         NOT NULL,
+    body       VARCHAR(1024),
+    tags       JSON         NOT NULL,
+    author     VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY `ARTICLE_REVISION_UNIQUE` (article_id, revision),
+    CONSTRAINT `fk_revision_article_id` FOREIGN KEY
+        (article_id) REFERENCES `svc-article`.articles (id)
+) ENGINE = InnoDB;
+
+INSERT INTO `svc-article`.article_revisions(article_id, revision, title, date, body, tags, author, created_at)
+SELECT articles.id,
+       1,
+       articles.title,
+       articles.date,
+       articles.body,
+       COALESCE((SELECT JSON_ARRAYAGG(tags.tag_title)
+                 FROM `svc-article`.article_tags
+                          INNER JOIN `svc-article`.tags on tags.id = article_tags.tag_id
+                 WHERE article_tags.article_id = articles.id), JSON_ARRAY()),
+       '',
+       NOW()
+FROM `svc-article`.articles;
@@ -0,0 +1,3 @@
+ALTER TABLE `svc-article`.articles
+    DROP INDEX `DELETED_AT_INDEX`,
+    DROP COLUMN deleted_at;
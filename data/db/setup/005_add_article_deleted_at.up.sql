@@ -0,0 +1,3 @@
+ALTER TABLE `svc-article`.articles
+    ADD COLUMN deleted_at DATETIME NULL DEFAULT NULL,
+    ADD INDEX `DELETED_AT_INDEX` (deleted_at);
@@ -0,0 +1,4 @@
+ALTER TABLE `svc-article`.articles
+    DROP INDEX `STATUS_PUBLISH_AT_INDEX`,
+    DROP COLUMN publish_at,
+    DROP COLUMN status;
@@ -0,0 +1,4 @@
+ALTER TABLE `svc-article`.articles
+    ADD COLUMN status     ENUM ('draft', 'scheduled', 'published', 'archived') NOT NULL DEFAULT 'published',
+    ADD COLUMN publish_at DATETIME NULL DEFAULT NULL,
+    ADD INDEX `STATUS_PUBLISH_AT_INDEX` (status, publish_at);
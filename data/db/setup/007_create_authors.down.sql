@@ -0,0 +1,3 @@
+DROP TABLE `svc-article`.article_authors;
+
+DROP TABLE `svc-article`.authors;
@@ -0,0 +1,20 @@
+CREATE TABLE `svc-article`.authors
+(
+    id    INT UNSIGNED AUTO_INCREMENT,
+    name  VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE,
+    bio   VARCHAR(1024),
+    PRIMARY KEY (id),
+    UNIQUE KEY `ID_UNIQUE` (id)
+) ENGINE = InnoDB;
+
+CREATE TABLE `svc-article`.article_authors
+(
+    article_id INT UNSIGNED,
+    author_id  INT UNSIGNED,
+    PRIMARY KEY (article_id, author_id),
+    CONSTRAINT `fk_article_authors_article_id` FOREIGN KEY
+        (article_id) REFERENCES `svc-article`.articles (id),
+    CONSTRAINT `fk_article_authors_author_id` FOREIGN KEY
+        (author_id) REFERENCES `svc-article`.authors (id)
+) ENGINE = InnoDB;
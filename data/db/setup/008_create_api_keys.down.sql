@@ -0,0 +1 @@
+DROP TABLE `svc-article`.api_keys;
@@ -0,0 +1,11 @@
+CREATE TABLE `svc-article`.api_keys
+(
+    id         INT UNSIGNED AUTO_INCREMENT,
+    name       VARCHAR(255) NOT NULL,
+    key_hash   CHAR(64)     NOT NULL,
+    scopes     VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at DATETIME     NOT NULL,
+    revoked_at DATETIME     NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY `KEY_HASH_UNIQUE` (key_hash)
+) ENGINE = InnoDB;
@@ -0,0 +1,4 @@
+ALTER TABLE `svc-article`.api_keys
+    DROP FOREIGN KEY `fk_api_keys_author_id`,
+    DROP COLUMN author_id,
+    DROP COLUMN role;
@@ -0,0 +1,4 @@
+ALTER TABLE `svc-article`.api_keys
+    ADD COLUMN role      VARCHAR(16)  NOT NULL DEFAULT 'reader' AFTER key_hash,
+    ADD COLUMN author_id INT UNSIGNED NULL DEFAULT NULL AFTER role,
+    ADD CONSTRAINT `fk_api_keys_author_id` FOREIGN KEY (author_id) REFERENCES `svc-article`.authors (id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE `svc-article`.articles
+    DROP COLUMN updated_at,
+    DROP COLUMN version;
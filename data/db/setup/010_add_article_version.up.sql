@@ -0,0 +1,3 @@
+ALTER TABLE `svc-article`.articles
+    ADD COLUMN version    INT UNSIGNED NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP;
@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"rest-article/config"
 	"rest-article/log"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/golang-migrate/migrate/source/file"
@@ -12,22 +14,101 @@ import (
 
 var logger = log.NewLogger().WithField("module", "database")
 
+// CreateDatabase opens the configured database with its pool settings and
+// waits for it to answer, for as long as database.connect.timeout allows.
 func CreateDatabase() (*sql.DB, error) {
 
-	param := "parseTime=true&multiStatements=true"
-	dataSourceName := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
-		config.App().Database.User,
-		config.App().Database.Pass,
-		config.App().Database.Host,
-		config.App().Database.Port,
-		config.App().Database.Schema,
-		param)
-
-	db, err := sql.Open(config.App().Database.Type, dataSourceName)
+	db, err := sql.Open(config.App().Database.Type, dataSourceName(config.App().Database.Schema, "parseTime=true&multiStatements=true"))
 	if err != nil {
 		logger.Errorf("error opening connection to db because: %v", err)
 		return nil, err
 	}
 
+	setPool(db)
+
+	connect := config.App().Database.Connect
+	if connect.Timeout <= 0 {
+		return db, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connect.Timeout)
+	defer cancel()
+
+	err = waitFor(ctx, db.PingContext, connect.Backoff, connect.MaxBackoff)
+	if err != nil {
+		logger.Errorf("database did not answer within %s because: %v", connect.Timeout, err)
+		db.Close()
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// OpenReplica opens the read replica at host and port with the pool settings
+// of the primary. It does not wait for the replica to answer, replicas are
+// health checked while the service runs.
+func OpenReplica(host string, port int) (*sql.DB, error) {
+
+	db, err := sql.Open(config.App().Database.Type, serverDataSourceName(host, port, config.App().Database.Schema, "parseTime=true"))
+	if err != nil {
+		logger.Errorf("error opening connection to replica %s:%d because: %v", host, port, err)
+		return nil, err
+	}
+
+	setPool(db)
+	return db, nil
+}
+
+func setPool(db *sql.DB) {
+	pool := config.App().Database.Pool
+	db.SetMaxOpenConns(pool.MaxOpen)
+	db.SetMaxIdleConns(pool.MaxIdle)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	setConnMaxIdleTime(db, pool.ConnMaxIdleTime)
+}
+
+// waitFor calls ping until it succeeds or ctx is done, the wait between calls
+// starts at backoff and doubles up to maxBackoff. The last error of ping is
+// returned when ctx is done first.
+func waitFor(ctx context.Context, ping func(ctx context.Context) error, backoff, maxBackoff time.Duration) error {
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Infof("Database answered after %d attempts", attempt)
+			}
+			return nil
+		}
+
+		logger.Warnf("Database did not answer on attempt %d, retrying in %s because: %v", attempt, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+
+		backoff *= 2
+		if maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// dataSourceName returns the DSN of the configured database server for the
+// schema, param is the query string of driver parameters
+func dataSourceName(schema, param string) string {
+	return serverDataSourceName(config.App().Database.Host, config.App().Database.Port, schema, param)
+}
+
+// serverDataSourceName returns the DSN of the database server at host and port
+// for the schema, with the configured user and password
+func serverDataSourceName(host string, port int, schema, param string) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
+		config.App().Database.User,
+		config.App().Database.Pass,
+		host,
+		port,
+		schema,
+		param)
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"github.com/golang-migrate/migrate"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"path/filepath"
+	"rest-article/config"
+
+	_ "github.com/golang-migrate/migrate/database/mysql"
+)
+
+// Migration table constants, seeds are versioned apart from the schema
+const (
+	MigrationsTable = "schema_migrations"
+	SeedsTable      = "seed_migrations"
+)
+
+// migrationsDatabase holds the migrations table, the migrations create the
+// service schema themselves
+const migrationsDatabase = "mysql"
+
+// NewMigrate returns the migrations found in the directory at path for the
+// configured database, versioned in the given table
+func NewMigrate(path, table string) (*migrate.Migrate, error) {
+
+	if config.App().Database.Type != "mysql" {
+		return nil, fmt.Errorf("migrations are not supported for database type %s", config.App().Database.Type)
+	}
+
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	param := "multiStatements=true&x-migrations-table=" + url.QueryEscape(table)
+	m, err := migrate.New("file://"+filepath.ToSlash(dir), "mysql://"+dataSourceName(migrationsDatabase, param))
+	if err != nil {
+		logger.Errorf("error opening migrations at %s because: %v", path, err)
+		return nil, err
+	}
+	m.Log = migrateLogger{logger}
+
+	return m, nil
+}
+
+// migrateLogger logs the progress of migrations
+type migrateLogger struct {
+	*logrus.Entry
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
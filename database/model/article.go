@@ -4,11 +4,41 @@ import (
 	"time"
 )
 
+// Article status constants
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
 type Article struct {
-	Id    int
-	Title string
-	Date  time.Time
-	Body  string
+	Id        int
+	Title     string
+	Date      time.Time
+	Body      string
+	Status    string
+	PublishAt *time.Time
+	DeletedAt *time.Time
+	// Version counts the updates of the article, starting at 1
+	Version   int
+	UpdatedAt time.Time
+}
+
+// ArticleImport is an article along with the names of its tags and the ids of
+// its authors, as created in bulk
+type ArticleImport struct {
+	Article   Article
+	Tags      []string
+	AuthorIds []int
+}
+
+// ArticleDump is everything stored about an article, as backed up and restored
+type ArticleDump struct {
+	Article   Article
+	Tags      []string
+	AuthorIds []int
+	Revisions []*Revision
 }
 
 type Tag struct {
@@ -16,7 +46,52 @@ type Tag struct {
 	Name string
 }
 
+// TagCount is a tag along with the number of articles carrying it
+type TagCount struct {
+	Id    int
+	Name  string
+	Count int
+}
+
+type Author struct {
+	Id    int
+	Name  string
+	Email string
+	Bio   string
+}
+
+type APIKey struct {
+	Id        int
+	Name      string
+	Hash      string
+	Role      string
+	AuthorId  int
+	Scopes    []string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// ArticleFilter narrows down an article listing, zero values are ignored
+type ArticleFilter struct {
+	AuthorId int
+	Tag      string
+	Limit    int
+	Offset   int
+}
+
 type ArticleTag struct {
 	ArticleId int
 	TagId     int
 }
+
+type Revision struct {
+	Id        int
+	ArticleId int
+	Revision  int
+	Title     string
+	Date      time.Time
+	Body      string
+	Tags      []string
+	Author    string
+	CreatedAt time.Time
+}
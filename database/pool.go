@@ -0,0 +1,15 @@
+//go:build go1.15
+// +build go1.15
+
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// setConnMaxIdleTime closes connections idle for longer than idleTime, 0 keeps
+// them open
+func setConnMaxIdleTime(db *sql.DB, idleTime time.Duration) {
+	db.SetConnMaxIdleTime(idleTime)
+}
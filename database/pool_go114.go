@@ -0,0 +1,17 @@
+//go:build !go1.15
+// +build !go1.15
+
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// setConnMaxIdleTime needs go 1.15, older builds only warn that idle
+// connections are left open
+func setConnMaxIdleTime(db *sql.DB, idleTime time.Duration) {
+	if idleTime > 0 {
+		logger.Warnf("database.pool.conn_max_idle_time needs go 1.15 or later and is ignored")
+	}
+}
@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Edit operation constants
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Edit is a run of consecutive tokens sharing the same operation
+type Edit struct {
+	Op   string
+	Text string
+}
+
+// MaxTokens bounds the tokens compared once the common prefix and suffix of
+// both inputs are set aside. The longest common subsequence table holds the
+// product of what is left on each side, which may be at most MaxTokens
+// squared.
+const MaxTokens = 2000
+
+// ErrTooLarge is returned for inputs too far apart to compare within MaxTokens
+var ErrTooLarge = errors.New("too many differences to compare")
+
+// Words returns the word level edits needed to turn from into to
+func Words(from, to string) ([]*Edit, error) {
+	result, err := tokens(strings.Fields(from), strings.Fields(to))
+	if err != nil {
+		return nil, err
+	}
+	return merge(result, " "), nil
+}
+
+type token struct {
+	op   string
+	text string
+}
+
+// tokens walks the longest common subsequence table of a and b to produce a
+// minimal sequence of equal, delete and insert tokens. The common prefix and
+// suffix are left out of the table, which only covers the part in between.
+func tokens(a, b []string) ([]token, error) {
+	var result []token
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		result = append(result, token{OpEqual, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	var suffix []token
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append(suffix, token{OpEqual, a[len(a)-1]})
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	if len(a)*len(b) > MaxTokens*MaxTokens {
+		return nil, ErrTooLarge
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, token{OpEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, token{OpDelete, a[i]})
+			i++
+		default:
+			result = append(result, token{OpInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		result = append(result, token{OpDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		result = append(result, token{OpInsert, b[j]})
+	}
+
+	for k := len(suffix) - 1; k >= 0; k-- {
+		result = append(result, suffix[k])
+	}
+
+	return result, nil
+}
+
+func merge(tokens []token, separator string) []*Edit {
+	var edits []*Edit
+	for _, t := range tokens {
+		if len(edits) > 0 && edits[len(edits)-1].Op == t.op {
+			edits[len(edits)-1].Text += separator + t.text
+			continue
+		}
+		edits = append(edits, &Edit{Op: t.op, Text: t.text})
+	}
+	return edits
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected []*Edit
+	}{
+		{"equal", "a b c", "a b c", []*Edit{{OpEqual, "a b c"}}},
+		{"insert", "a c", "a b c", []*Edit{{OpEqual, "a"}, {OpInsert, "b"}, {OpEqual, "c"}}},
+		{"delete", "a b c", "a c", []*Edit{{OpEqual, "a"}, {OpDelete, "b"}, {OpEqual, "c"}}},
+		{"replace", "a b c", "a x y c", []*Edit{{OpEqual, "a"}, {OpDelete, "b"}, {OpInsert, "x y"}, {OpEqual, "c"}}},
+		{"append", "a b", "a b c", []*Edit{{OpEqual, "a b"}, {OpInsert, "c"}}},
+		{"from empty", "", "a b", []*Edit{{OpInsert, "a b"}}},
+		{"to empty", "a b", "", []*Edit{{OpDelete, "a b"}}},
+		{"both empty", "", "", nil},
+		{"whitespace", "a  b\n", "a b", []*Edit{{OpEqual, "a b"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			edits, err := Words(test.from, test.to)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, edits)
+		})
+	}
+}
+
+func TestWordsTooLarge(t *testing.T) {
+	from := strings.Repeat("a ", MaxTokens+1)
+	to := strings.Repeat("b ", MaxTokens+1)
+
+	_, err := Words(from, to)
+	assert.Equal(t, ErrTooLarge, err)
+
+	// only the part between the common prefix and suffix counts
+	edits, err := Words("x "+from+"y", "x "+from+"z")
+	assert.NoError(t, err)
+	assert.Equal(t, []*Edit{{OpEqual, "x " + strings.TrimSpace(from)}, {OpDelete, "y"}, {OpInsert, "z"}}, edits)
+
+	// as does an empty side, which needs no table
+	_, err = Words("", to+to)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// atomNamespace is the XML namespace of Atom 1.0 (RFC 4287)
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Authors []atomPerson `xml:"author,omitempty"`
+	Entries []atomEntry  `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Authors    []atomPerson   `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category,omitempty"`
+	Content    atomContent    `xml:"content"`
+}
+
+// Atom writes the feed as an Atom 1.0 document
+func (feed *Feed) Atom() ([]byte, error) {
+
+	document := atomFeed{
+		ID:      feed.ID,
+		Title:   feed.Title,
+		Updated: feed.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate"},
+			{Href: feed.Self, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: []atomEntry{},
+	}
+	if feed.Author != "" {
+		document.Authors = []atomPerson{{Name: feed.Author}}
+	}
+
+	for _, entry := range feed.Entries {
+		atomEntry := atomEntry{
+			ID:        entry.ID,
+			Title:     entry.Title,
+			Updated:   entry.Updated.UTC().Format(time.RFC3339),
+			Published: entry.Published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: entry.Link, Rel: "alternate"}},
+			Content:   atomContent{Type: "text", Body: entry.Content},
+		}
+		for _, author := range entry.Authors {
+			atomEntry.Authors = append(atomEntry.Authors, atomPerson{Name: author})
+		}
+		for _, category := range entry.Categories {
+			atomEntry.Categories = append(atomEntry.Categories, atomCategory{Term: category})
+		}
+		document.Entries = append(document.Entries, atomEntry)
+	}
+
+	return marshal(document)
+}
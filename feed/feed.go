@@ -0,0 +1,54 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Feed is a list of entries newest first, written out as Atom or RSS
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	// Link is the HTML page of the feed, Self the url the feed is served at
+	Link    string
+	Self    string
+	Author  string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is a single article of a Feed
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	Content    string
+	Authors    []string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// TagURI returns a tag URI (RFC 4151) such as
+// tag:example.com,2020-04-20:/articles/1, which stays the same for the
+// resource however the feed is served.
+func TagURI(authority string, date time.Time, specific string) string {
+	return fmt.Sprintf("tag:%s,%s:%s", authority, date.Format("2006-01-02"), specific)
+}
+
+// marshal writes the document behind an XML declaration
+func marshal(document interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
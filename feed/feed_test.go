@@ -0,0 +1,72 @@
+package feed
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestFeed() *Feed {
+	published := time.Date(2020, 4, 20, 9, 30, 0, 0, time.UTC)
+	return &Feed{
+		ID:          "tag:example.com,2020-04-20:/feeds/articles",
+		Title:       "Articles",
+		Description: "The newest articles",
+		Link:        "http://example.com/v2/articles",
+		Self:        "http://example.com/feeds/articles.atom",
+		Author:      "rest-article",
+		Updated:     published,
+		Entries: []Entry{{
+			ID:         TagURI("example.com", published, "/articles/1"),
+			Title:      "Sleep & health",
+			Link:       "http://example.com/v2/articles/1",
+			Content:    "Why sleep matters",
+			Authors:    []string{"Andrew Jelwan"},
+			Categories: []string{"science", "health"},
+			Published:  published,
+			Updated:    published,
+		}},
+	}
+}
+
+func TestTagURI(t *testing.T) {
+	assert.Equal(t, "tag:example.com,2020-04-20:/articles/1",
+		TagURI("example.com", time.Date(2020, 4, 20, 23, 0, 0, 0, time.UTC), "/articles/1"))
+}
+
+func TestAtom(t *testing.T) {
+	body, err := newTestFeed().Atom()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(body), xml.Header))
+
+	var document atomFeed
+	assert.Nil(t, xml.Unmarshal(body, &document))
+	assert.Equal(t, atomNamespace, document.XMLName.Space)
+	assert.Equal(t, "2020-04-20T09:30:00Z", document.Updated)
+	assert.Len(t, document.Entries, 1)
+
+	entry := document.Entries[0]
+	assert.Equal(t, "tag:example.com,2020-04-20:/articles/1", entry.ID)
+	assert.Equal(t, "Sleep & health", entry.Title)
+	assert.Equal(t, []atomCategory{{Term: "science"}, {Term: "health"}}, entry.Categories)
+	assert.Equal(t, []atomPerson{{Name: "Andrew Jelwan"}}, entry.Authors)
+}
+
+func TestRSS(t *testing.T) {
+	body, err := newTestFeed().RSS()
+	assert.Nil(t, err)
+	assert.Contains(t, string(body), `<atom:link href="http://example.com/feeds/articles.atom" rel="self"`)
+	assert.Contains(t, string(body), `<dc:creator>Andrew Jelwan</dc:creator>`)
+
+	var document rss
+	assert.Nil(t, xml.Unmarshal(body, &document))
+	assert.Equal(t, "2.0", document.Version)
+	assert.Len(t, document.Channel.Items, 1)
+
+	item := document.Channel.Items[0]
+	assert.Equal(t, "tag:example.com,2020-04-20:/articles/1", item.GUID.Value)
+	assert.Equal(t, "Mon, 20 Apr 2020 09:30:00 +0000", item.PubDate)
+	assert.Equal(t, []string{"science", "health"}, item.Categories)
+}
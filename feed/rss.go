@@ -0,0 +1,80 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// dublinCoreNamespace carries the dc:creator element naming item authors, RSS
+// itself only knows authors by email address.
+const dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creators    []string `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category,omitempty"`
+}
+
+// RSS writes the feed as an RSS 2.0 document
+func (feed *Feed) RSS() ([]byte, error) {
+
+	document := rss{
+		Version: "2.0",
+		AtomNS:  atomNamespace,
+		DCNS:    dublinCoreNamespace,
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			Self:          rssLink{Href: feed.Self, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			Items:         []rssItem{},
+		},
+	}
+
+	for _, entry := range feed.Entries {
+		document.Channel.Items = append(document.Channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Content,
+			GUID:        rssGUID{IsPermaLink: "false", Value: entry.ID},
+			PubDate:     entry.Published.UTC().Format(time.RFC1123Z),
+			Creators:    entry.Authors,
+			Categories:  entry.Categories,
+		})
+	}
+
+	return marshal(document)
+}
@@ -0,0 +1,67 @@
+package job
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"rest-article/field"
+	"rest-article/log"
+	"rest-article/repo"
+	"time"
+)
+
+// Purger permanently removes soft deleted articles once they are older than the
+// retention window.
+type Purger struct {
+	ctx       context.Context
+	repo      repo.Repo
+	retention time.Duration
+	interval  time.Duration
+	logger    *logrus.Entry
+}
+
+func NewPurger(ctx context.Context, articleRepo repo.Repo, retention, interval time.Duration) *Purger {
+	return &Purger{
+		ctx:       ctx,
+		repo:      articleRepo,
+		retention: retention,
+		interval:  interval,
+		logger:    log.NewLogger().WithContext(ctx).WithField("module", "job"),
+	}
+}
+
+// Run purges on every interval until the context is done, it returns straight
+// away when no retention or interval is configured.
+func (purger *Purger) Run() {
+	if purger.retention <= 0 || purger.interval <= 0 {
+		purger.logger.Infof("article purge disabled")
+		return
+	}
+
+	ticker := time.NewTicker(purger.interval)
+	defer ticker.Stop()
+
+	for {
+		purger.Purge()
+
+		select {
+		case <-purger.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes every article deleted before the retention window
+func (purger *Purger) Purge() {
+	count, err := purger.repo.PurgeArticles(time.Now().UTC().Add(-purger.retention))
+	if err != nil {
+		purger.logger.
+			WithFields(field.ErrorFields("Purge", "PurgeArticles")).
+			Errorf("failed to purge deleted articles because: %v", err)
+		return
+	}
+
+	if count > 0 {
+		purger.logger.Infof("purged %d deleted articles", count)
+	}
+}
@@ -0,0 +1,88 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"rest-article/field"
+	"rest-article/log"
+	"rest-article/repo"
+	"rest-article/search"
+	"time"
+)
+
+// Scheduler publishes scheduled articles once their publish time has passed
+type Scheduler struct {
+	ctx      context.Context
+	repo     repo.Repo
+	index    search.Index
+	interval time.Duration
+	logger   *logrus.Entry
+}
+
+func NewScheduler(ctx context.Context, articleRepo repo.Repo, index search.Index, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		ctx:      ctx,
+		repo:     articleRepo,
+		index:    index,
+		interval: interval,
+		logger:   log.NewLogger().WithContext(ctx).WithField("module", "job"),
+	}
+}
+
+// Run publishes due articles on every interval until the context is done
+func (scheduler *Scheduler) Run() {
+	if scheduler.interval <= 0 {
+		scheduler.logger.Infof("article scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.Publish()
+
+		select {
+		case <-scheduler.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish promotes every due article and adds it to the search index
+func (scheduler *Scheduler) Publish() {
+	ids, err := scheduler.repo.PublishScheduledArticles(time.Now().UTC())
+	if err != nil {
+		scheduler.logger.
+			WithFields(field.ErrorFields("Publish", "PublishScheduledArticles")).
+			Errorf("failed to publish scheduled articles because: %v", err)
+		return
+	}
+
+	// the articles were just published, a replica may not have them yet
+	primary := scheduler.repo.Primary()
+	for _, id := range ids {
+		article, tags, err := primary.GetArticleByID(fmt.Sprintf("%d", id))
+		if err != nil {
+			scheduler.logger.
+				WithFields(field.ErrorFields("Publish", "GetArticleByID")).
+				Errorf("failed to load published article %d because: %v", id, err)
+			continue
+		}
+
+		var tagsList []string
+		for _, tag := range tags {
+			tagsList = append(tagsList, tag.Name)
+		}
+
+		if err := scheduler.index.Index(*article, tagsList); err != nil {
+			scheduler.logger.Errorf("error indexing article %d because: %v", id, err)
+		}
+	}
+
+	if len(ids) > 0 {
+		scheduler.logger.Infof("published %d scheduled articles", len(ids))
+	}
+}
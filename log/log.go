@@ -33,20 +33,28 @@ type Logger interface {
 	Warnln(args ...interface{})
 }
 
-var defaultLogger *logrus.Logger
+// defaultLogger is the one logger of the process, every module logs through
+// it with fields of its own
+var defaultLogger = newLogrusLogger()
 
-// NewLogger returns logrus instance
+// NewLogger returns the logger of the process, whose level follows SetLevel
 func NewLogger() *logrus.Logger {
-	return newLogrusLogger()
+	return defaultLogger
 }
 
 func newLogrusLogger() *logrus.Logger {
 
 	l := logrus.New()
+	l.AddHook(redactHook{})
 
 	return l
 }
 
+// SetLevel sets the level of the logger
+func SetLevel(level logrus.Level) {
+	defaultLogger.SetLevel(level)
+}
+
 // Fields is a map string interface to define field in the structured log
 type Fields map[string]interface{}
 
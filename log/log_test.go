@@ -0,0 +1,18 @@
+package log
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	level := NewLogger().GetLevel()
+	t.Cleanup(func() { SetLevel(level) })
+
+	// every module shares the logger, so a reload reaches all of them
+	assert.Same(t, NewLogger(), NewLogger())
+
+	SetLevel(logrus.WarnLevel)
+	assert.Equal(t, logrus.WarnLevel, NewLogger().GetLevel())
+}
@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"fmt"
+	"rest-article/auth"
+)
+
+// Action constants
+const (
+	ActionRead   = "read"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Resource kind constants
+const (
+	KindArticle = "article"
+	KindAuthor  = "author"
+)
+
+// Resource is the target of an action, OwnerIds holds the ids of the authors
+// that own it.
+type Resource struct {
+	Kind     string
+	OwnerIds []int
+}
+
+// Decision is the outcome of a policy check, Reason explains any denial
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+func allow() Decision {
+	return Decision{Allowed: true}
+}
+
+func deny(format string, args ...interface{}) Decision {
+	return Decision{Allowed: false, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Authorize decides whether the principal may perform the action on the
+// resource. Readers are read only, authors may only change what they own and
+// editors and admins may change anything. A nil principal means authentication
+// is disabled and everything is allowed.
+func Authorize(principal *auth.Principal, action string, resource Resource) Decision {
+	if principal == nil {
+		return allow()
+	}
+
+	if action == ActionRead {
+		return allow()
+	}
+
+	switch principal.Role {
+	case auth.RoleAdmin, auth.RoleEditor:
+		return allow()
+	case auth.RoleAuthor:
+		if principal.AuthorId == 0 {
+			return deny("%s %s is not linked to an author", auth.RoleAuthor, principal.Subject)
+		}
+		if resource.Kind == KindAuthor && action != ActionUpdate {
+			return deny("only editors can %s an %s", action, resource.Kind)
+		}
+		if !owns(principal.AuthorId, resource.OwnerIds) {
+			return deny("authors can only %s their own %s", action, resource.Kind)
+		}
+		return allow()
+	case auth.RoleReader, "":
+		return deny("readers cannot %s an %s", action, resource.Kind)
+	default:
+		return deny("unknown role %s", principal.Role)
+	}
+}
+
+func owns(authorID int, ownerIDs []int) bool {
+	for _, id := range ownerIDs {
+		if id == authorID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"rest-article/auth"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	reader := &auth.Principal{Subject: "reader", Role: auth.RoleReader}
+	author := &auth.Principal{Subject: "author", Role: auth.RoleAuthor, AuthorId: 7}
+	unlinkedAuthor := &auth.Principal{Subject: "unlinked", Role: auth.RoleAuthor}
+	editor := &auth.Principal{Subject: "editor", Role: auth.RoleEditor}
+	admin := &auth.Principal{Subject: "admin", Role: auth.RoleAdmin}
+	unknown := &auth.Principal{Subject: "unknown", Role: "owner"}
+
+	ownArticle := Resource{Kind: KindArticle, OwnerIds: []int{3, 7}}
+	otherArticle := Resource{Kind: KindArticle, OwnerIds: []int{3}}
+	ownProfile := Resource{Kind: KindAuthor, OwnerIds: []int{7}}
+	newAuthor := Resource{Kind: KindAuthor}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		action    string
+		resource  Resource
+		allowed   bool
+	}{
+		{"auth disabled", nil, ActionDelete, otherArticle, true},
+
+		{"reader reads", reader, ActionRead, otherArticle, true},
+		{"reader creates", reader, ActionCreate, ownArticle, false},
+		{"reader updates", reader, ActionUpdate, otherArticle, false},
+		{"reader deletes", reader, ActionDelete, otherArticle, false},
+
+		{"author reads other", author, ActionRead, otherArticle, true},
+		{"author creates own", author, ActionCreate, ownArticle, true},
+		{"author creates for others", author, ActionCreate, otherArticle, false},
+		{"author updates own", author, ActionUpdate, ownArticle, true},
+		{"author updates other", author, ActionUpdate, otherArticle, false},
+		{"author deletes own", author, ActionDelete, ownArticle, true},
+		{"author deletes other", author, ActionDelete, otherArticle, false},
+		{"author updates own profile", author, ActionUpdate, ownProfile, true},
+		{"author updates other profile", author, ActionUpdate, Resource{Kind: KindAuthor, OwnerIds: []int{3}}, false},
+		{"author deletes own profile", author, ActionDelete, ownProfile, false},
+		{"author creates author", author, ActionCreate, newAuthor, false},
+		{"unlinked author updates", unlinkedAuthor, ActionUpdate, ownArticle, false},
+
+		{"editor creates", editor, ActionCreate, otherArticle, true},
+		{"editor updates other", editor, ActionUpdate, otherArticle, true},
+		{"editor deletes other", editor, ActionDelete, otherArticle, true},
+		{"editor creates author", editor, ActionCreate, newAuthor, true},
+
+		{"admin deletes other", admin, ActionDelete, otherArticle, true},
+
+		{"unknown role reads", unknown, ActionRead, otherArticle, true},
+		{"unknown role updates", unknown, ActionUpdate, ownArticle, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decision := Authorize(test.principal, test.action, test.resource)
+
+			assert.Equal(t, test.allowed, decision.Allowed)
+			if !decision.Allowed {
+				assert.NotEmpty(t, decision.Reason)
+			}
+		})
+	}
+}
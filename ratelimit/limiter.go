@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the number of calls between sweeps of idle buckets
+const sweepEvery = 1000
+
+// Result describes the state of a client's limit after a request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter is a token bucket per client key, every bucket holds up to burst
+// tokens and refills at rate tokens per second.
+type Limiter struct {
+	rate    float64
+	burst   int
+	now     func() time.Time
+	mu      sync.Mutex
+	calls   int
+	buckets map[string]*bucket
+}
+
+// NewLimiter returns a limiter allowing rate requests per second with bursts of
+// up to burst requests.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:    rate,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of key if one is left
+func (limiter *Limiter) Allow(key string) Result {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	b, ok := limiter.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limiter.burst), last: now}
+		limiter.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limiter.burst), b.tokens+now.Sub(b.last).Seconds()*limiter.rate)
+	b.last = now
+
+	result := Result{Limit: limiter.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = limiter.refill(1 - b.tokens)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = limiter.refill(float64(limiter.burst) - b.tokens)
+
+	return result
+}
+
+// refill returns how long it takes to refill the tokens
+func (limiter *Limiter) refill(tokens float64) time.Duration {
+	return time.Duration(tokens / limiter.rate * float64(time.Second))
+}
+
+// sweep drops the buckets that have been idle long enough to be full again,
+// they are no different from a new bucket.
+func (limiter *Limiter) sweep(now time.Time) {
+	limiter.calls++
+	if limiter.calls < sweepEvery {
+		return
+	}
+	limiter.calls = 0
+
+	full := limiter.refill(float64(limiter.burst))
+	for key, b := range limiter.buckets {
+		if now.Sub(b.last) >= full {
+			delete(limiter.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	c := &clock{now: time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(1, 2)
+	limiter.now = c.Now
+
+	first := limiter.Allow("client")
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 2, first.Limit)
+	assert.Equal(t, 1, first.Remaining)
+
+	assert.True(t, limiter.Allow("client").Allowed)
+
+	denied := limiter.Allow("client")
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.Equal(t, 2*time.Second, denied.Reset)
+
+	assert.True(t, limiter.Allow("other").Allowed)
+
+	c.now = c.now.Add(time.Second)
+	assert.True(t, limiter.Allow("client").Allowed)
+	assert.False(t, limiter.Allow("client").Allowed)
+}
+
+func TestLimiterSweep(t *testing.T) {
+	c := &clock{now: time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(10, 10)
+	limiter.now = c.Now
+
+	limiter.Allow("idle")
+	c.now = c.now.Add(time.Minute)
+	for i := 0; i < sweepEvery; i++ {
+		limiter.Allow("busy")
+	}
+
+	_, ok := limiter.buckets["idle"]
+	assert.False(t, ok)
+}
+
+func TestQuota(t *testing.T) {
+	c := &clock{now: time.Date(2020, 3, 1, 23, 0, 0, 0, time.UTC)}
+	quota := NewQuota(2)
+	quota.now = c.Now
+
+	assert.True(t, quota.Use("client").Allowed)
+	second := quota.Use("client")
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	denied := quota.Use("client")
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Hour, denied.RetryAfter)
+
+	c.now = c.now.Add(time.Hour)
+	assert.True(t, quota.Use("client").Allowed)
+}
+
+func TestQuotaNewDayKeepsOtherClients(t *testing.T) {
+	c := &clock{now: time.Date(2020, 3, 1, 23, 0, 0, 0, time.UTC)}
+	quota := NewQuota(2)
+	quota.now = c.Now
+
+	quota.Use("yesterday")
+	c.now = c.now.Add(2 * time.Hour)
+	quota.Use("today")
+
+	// the new day of one client does not reset the count of another
+	assert.True(t, quota.Use("yesterday").Allowed)
+	assert.Equal(t, 0, quota.Use("today").Remaining)
+}
+
+func TestQuotaSweep(t *testing.T) {
+	c := &clock{now: time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)}
+	quota := NewQuota(sweepEvery + 1)
+	quota.now = c.Now
+
+	quota.Use("idle")
+	c.now = c.now.Add(24 * time.Hour)
+	for i := 0; i < sweepEvery; i++ {
+		quota.Use("busy")
+	}
+
+	_, ok := quota.counts["idle"]
+	assert.False(t, ok)
+}
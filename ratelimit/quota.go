@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type dayCount struct {
+	day   time.Time
+	count int
+}
+
+// Quota counts the requests of every client key per UTC day
+type Quota struct {
+	limit  int
+	now    func() time.Time
+	mu     sync.Mutex
+	calls  int
+	counts map[string]*dayCount
+}
+
+// NewQuota returns a quota allowing limit requests per client per day
+func NewQuota(limit int) *Quota {
+	return &Quota{
+		limit:  limit,
+		now:    time.Now,
+		counts: make(map[string]*dayCount),
+	}
+}
+
+// Use counts a request against the quota of key unless it is used up
+func (quota *Quota) Use(key string) Result {
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+
+	now := quota.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	quota.sweep(day)
+
+	counted, ok := quota.counts[key]
+	if !ok {
+		counted = &dayCount{day: day}
+		quota.counts[key] = counted
+	} else if counted.day.Before(day) {
+		// a new day has started for the client
+		counted.day = day
+		counted.count = 0
+	}
+
+	reset := day.Add(24 * time.Hour).Sub(now)
+	result := Result{Limit: quota.limit, Reset: reset}
+	if counted.count < quota.limit {
+		counted.count++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = reset
+	}
+
+	result.Remaining = quota.limit - counted.count
+
+	return result
+}
+
+// sweep drops the counts of clients that have not called since an earlier day,
+// they are no different from a new count.
+func (quota *Quota) sweep(day time.Time) {
+	quota.calls++
+	if quota.calls < sweepEvery {
+		return
+	}
+	quota.calls = 0
+
+	for key, counted := range quota.counts {
+		if counted.day.Before(day) {
+			delete(quota.counts, key)
+		}
+	}
+}
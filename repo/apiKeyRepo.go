@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"database/sql"
+	"rest-article/database/model"
+	"rest-article/field"
+	"strings"
+	"time"
+)
+
+func (articleRepo *ArticleRepo) GetAPIKeys() ([]*model.APIKey, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `name`, `key_hash`, `role`, `author_id`, `scopes`, `created_at`, `revoked_at` "+
+			"FROM `svc-article`.api_keys ORDER BY `id`")
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetAPIKeys", "QueryContext")).
+			Errorf("error selecting api keys because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetAPIKeys", "Scan")).
+				Errorf("failed to read api keys because %v", err)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (articleRepo *ArticleRepo) GetAPIKeyByHash(hash string) (*model.APIKey, error) {
+
+	row := articleRepo.db.QueryRowContext(articleRepo.ctx,
+		"SELECT `id`, `name`, `key_hash`, `role`, `author_id`, `scopes`, `created_at`, `revoked_at` "+
+			"FROM `svc-article`.api_keys WHERE `key_hash` = ?", hash)
+
+	key, err := scanAPIKey(row)
+	if err != nil && err != sql.ErrNoRows {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetAPIKeyByHash", "Scan")).
+			Errorf("failed to query api key because %v", err)
+	}
+
+	return key, err
+}
+
+func (articleRepo *ArticleRepo) CreateAPIKey(key model.APIKey) (*model.APIKey, error) {
+
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now().UTC()
+	}
+
+	result, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"INSERT INTO `svc-article`.api_keys(`name`, `key_hash`, `role`, `author_id`, `scopes`, `created_at`) "+
+			"VALUES (?, ?, ?, ?, ?, ?)",
+		key.Name, key.Hash, key.Role, sql.NullInt64{Int64: int64(key.AuthorId), Valid: key.AuthorId != 0},
+		strings.Join(key.Scopes, " "), key.CreatedAt)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateAPIKey", "ExecContext")).
+			Errorf("error executing insert api key statement: %v", err)
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		articleRepo.logger.Errorf("error retrieving api key ID: %v", err)
+		return nil, err
+	}
+	key.Id = int(id)
+
+	return &key, nil
+}
+
+func (articleRepo *ArticleRepo) RevokeAPIKey(id string) error {
+
+	result, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.api_keys SET `revoked_at` = ? WHERE `id` = ? AND `revoked_at` IS NULL",
+		time.Now().UTC(), id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("RevokeAPIKey", "ExecContext")).
+			Errorf("error executing revoke api key statement: %v", err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var authorID sql.NullInt64
+	err := row.Scan(&key.Id, &key.Name, &key.Hash, &key.Role, &authorID, &scopes, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.AuthorId = int(authorID.Int64)
+	key.Scopes = strings.Fields(scopes)
+
+	return &key, nil
+}
@@ -3,31 +3,78 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"rest-article/database/model"
 	"rest-article/field"
 	"rest-article/log"
+	"strings"
+	"time"
 )
 
+// DefaultListLimit is the page size used when an article listing does not set one
+const DefaultListLimit = 20
+
+// DateFormat is the format DATE columns are compared in
+const DateFormat = "2006-01-02"
+
+// ErrVersionConflict is returned when an article is updated from a version
+// that is no longer its latest
+var ErrVersionConflict = errors.New("article has been changed since it was read")
+
 type Repo interface {
 	GetArticleByID(id string) (*model.Article, []*model.Tag, error)
-	CountTagForDateName(name, date string) (int, error)
-	GetRelatedTagForDateAndName(name, date string) ([]string, error)
-	GetArticleIDForDateAndTag(name, date string) ([]string, error)
-	CreateArticle(article model.Article, tags []string) (*model.Article, []*model.Tag, error)
+	ListArticles(filter model.ArticleFilter) ([]*model.Article, error)
+	GetArticleTags(articleIDs []int) (map[int][]string, error)
+	GetAdminArticleByID(id string) (*model.Article, []*model.Tag, error)
+	PublishScheduledArticles(now time.Time) ([]int, error)
+	CountTagForDateName(name string, date time.Time) (int, error)
+	GetRelatedTagForDateAndName(name string, date time.Time) ([]string, error)
+	GetArticleIDForDateAndTag(name string, date time.Time) ([]string, error)
+	GetTagCounts() ([]*model.TagCount, error)
+	MergeTags(from, into string) (int, error)
+	CreateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error)
+	CreateArticles(articles []model.ArticleImport, author string) error
+	BeginImport() (ImportTx, error)
+	UpdateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error)
+	DeleteArticle(id string) error
+	RestoreArticle(id string) error
+	GetDeletedArticles() ([]*model.Article, error)
+	PurgeArticles(deletedBefore time.Time) (int, error)
+	GetRevisions(articleID string) ([]*model.Revision, error)
+	GetRevision(articleID string, revision int) (*model.Revision, error)
+	GetAuthors() ([]*model.Author, error)
+	GetAuthorByID(id string) (*model.Author, error)
+	CreateAuthor(author model.Author) (*model.Author, error)
+	UpdateAuthor(author model.Author) (*model.Author, error)
+	DeleteAuthor(id string) error
+	GetArticleAuthors(articleIDs []int) (map[int][]*model.Author, error)
+	SetArticleAuthors(articleID int, authorIDs []int) error
+	DumpArticles(afterID int, limit int) ([]*model.ArticleDump, error)
+	LoadAuthor(author model.Author) error
+	LoadArticle(dump model.ArticleDump) error
+	GetAPIKeys() ([]*model.APIKey, error)
+	GetAPIKeyByHash(hash string) (*model.APIKey, error)
+	CreateAPIKey(key model.APIKey) (*model.APIKey, error)
+	RevokeAPIKey(id string) error
+	Primary() Repo
 	getTagsByName(ctx context.Context, tagNames []string) ([]*model.Tag, error)
 	getTagById(ctx context.Context, id int) (*model.Tag, error)
 	getArticleTagsByArticleID(ctx context.Context, articleID string) ([]int, error)
 	insertTag(ctx context.Context, tagName string) (int, error)
 	insertArticle(ctx context.Context, article model.Article) error
 	insertArticleTags(ctx context.Context, articleID int, tagIDs []int) error
+	insertRevision(ctx context.Context, revision model.Revision) error
 }
 
 type ArticleRepo struct {
-	ctx    context.Context
-	db     *sql.DB
-	logger *logrus.Entry
+	ctx context.Context
+	db  *sql.DB
+	// replicas serve GetArticleByID and the tag aggregates when set
+	replicas *Replicas
+	logger   *logrus.Entry
 }
 
 func NewArticleRepo(ctx context.Context, db *sql.DB) Repo {
@@ -40,10 +87,74 @@ func NewArticleRepo(ctx context.Context, db *sql.DB) Repo {
 	return repo
 }
 
-func (articleRepo *ArticleRepo) GetArticleByID(id string) (*model.Article, []*model.Tag, error) {
+// NewReplicatedArticleRepo returns the repo writing to primary and reading
+// articles by id and tag aggregates from replicas, falling back to primary
+// while no replica is healthy.
+func NewReplicatedArticleRepo(ctx context.Context, primary *sql.DB, replicas *Replicas) Repo {
+	repo := &ArticleRepo{
+		ctx:      ctx,
+		db:       primary,
+		replicas: replicas,
+		logger:   log.NewLogger().WithContext(ctx).WithField("module", "repo"),
+	}
+
+	return repo
+}
+
+// Primary returns the repo with every read going to the primary, for reads
+// that must see writes made just before
+func (articleRepo *ArticleRepo) Primary() Repo {
+	return &ArticleRepo{
+		ctx:    articleRepo.ctx,
+		db:     articleRepo.db,
+		logger: articleRepo.logger,
+	}
+}
+
+// read runs operation against a healthy replica, or against the primary when
+// there is none. A replica failing with a transient error is left out of the
+// next reads and the operation is run again against the primary.
+func (articleRepo *ArticleRepo) read(operation func(reader *ArticleRepo) error) error {
+	if articleRepo.replicas == nil {
+		return operation(articleRepo)
+	}
+
+	db := articleRepo.replicas.pick()
+	if db == nil {
+		return operation(articleRepo)
+	}
+
+	err := operation(&ArticleRepo{ctx: articleRepo.ctx, db: db, logger: articleRepo.logger})
+	if IsTransient(err) {
+		articleRepo.replicas.failed(db, err)
+		return operation(articleRepo)
+	}
+	return err
+}
+
+func (articleRepo *ArticleRepo) GetArticleByID(id string) (article *model.Article, tags []*model.Tag, err error) {
+	err = articleRepo.read(func(reader *ArticleRepo) error {
+		article, tags, err = reader.getArticle(id, true)
+		return err
+	})
+	return article, tags, err
+}
+
+func (articleRepo *ArticleRepo) GetAdminArticleByID(id string) (*model.Article, []*model.Tag, error) {
+	return articleRepo.getArticle(id, false)
+}
+
+// getArticle loads a non deleted article and its tags, publishedOnly hides any
+// article that has not been published yet.
+func (articleRepo *ArticleRepo) getArticle(id string, publishedOnly bool) (*model.Article, []*model.Tag, error) {
 
-	statement, err := articleRepo.db.PrepareContext(articleRepo.ctx,
-		"Select `id`, `title`, `date`, `body` FROM `svc-article`.articles where id = ?")
+	query := "Select `id`, `title`, `date`, `body`, `status`, `publish_at`, `version`, `updated_at` " +
+		"FROM `svc-article`.articles where id = ? AND deleted_at IS NULL"
+	if publishedOnly {
+		query += " AND status = '" + model.StatusPublished + "'"
+	}
+
+	statement, err := articleRepo.db.PrepareContext(articleRepo.ctx, query)
 	if err != nil {
 		articleRepo.logger.
 			WithFields(field.ErrorFields("GetArticleByID", "PrepareContext")).
@@ -52,7 +163,8 @@ func (articleRepo *ArticleRepo) GetArticleByID(id string) (*model.Article, []*mo
 	}
 
 	var article model.Article
-	err = statement.QueryRow(id).Scan(&article.Id, &article.Title, &article.Date, &article.Body)
+	err = statement.QueryRow(id).Scan(&article.Id, &article.Title, &article.Date, &article.Body,
+		&article.Status, &article.PublishAt, &article.Version, &article.UpdatedAt)
 	if err != nil {
 		articleRepo.logger.
 			WithFields(field.ErrorFields("GetArticleByID", "QueryRow")).
@@ -83,14 +195,123 @@ func (articleRepo *ArticleRepo) GetArticleByID(id string) (*model.Article, []*mo
 	return &article, tags, nil
 }
 
-func (articleRepo *ArticleRepo) CountTagForDateName(name, date string) (int, error) {
+// ListArticles returns published articles newest first
+func (articleRepo *ArticleRepo) ListArticles(filter model.ArticleFilter) ([]*model.Article, error) {
+
+	conditions := []string{
+		"articles.deleted_at IS NULL",
+		"articles.status = '" + model.StatusPublished + "'",
+	}
+	var args []interface{}
+
+	if filter.AuthorId > 0 {
+		conditions = append(conditions, "EXISTS ("+
+			"SELECT 1 FROM `svc-article`.article_authors "+
+			"WHERE article_authors.article_id = articles.id AND article_authors.author_id = ?)")
+		args = append(args, filter.AuthorId)
+	}
+
+	if filter.Tag != "" {
+		conditions = append(conditions, "EXISTS ("+
+			"SELECT 1 FROM `svc-article`.article_tags "+
+			"INNER JOIN `svc-article`.tags on tags.id = article_tags.tag_id "+
+			"WHERE article_tags.article_id = articles.id AND tags.tag_title = ?)")
+		args = append(args, filter.Tag)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	args = append(args, limit, filter.Offset)
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT articles.id, articles.title, articles.date, articles.body, articles.status, articles.publish_at, "+
+			"articles.version, articles.updated_at "+
+			"FROM `svc-article`.articles "+
+			"WHERE "+strings.Join(conditions, " AND ")+" "+
+			"ORDER BY articles.date DESC, articles.id DESC "+
+			"LIMIT ? OFFSET ?", args...)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("ListArticles", "QueryContext")).
+			Errorf("error selecting articles because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []*model.Article
+	for rows.Next() {
+		var article model.Article
+		err := rows.Scan(&article.Id, &article.Title, &article.Date, &article.Body,
+			&article.Status, &article.PublishAt, &article.Version, &article.UpdatedAt)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("ListArticles", "Scan")).
+				Errorf("failed to read articles because %v", err)
+			return nil, err
+		}
+		articles = append(articles, &article)
+	}
+
+	return articles, rows.Err()
+}
+
+// GetArticleTags returns the tag names of each of the given articles
+func (articleRepo *ArticleRepo) GetArticleTags(articleIDs []int) (map[int][]string, error) {
+
+	tags := make(map[int][]string)
+	if len(articleIDs) == 0 {
+		return tags, nil
+	}
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT article_tags.article_id, tags.tag_title "+
+			"FROM `svc-article`.article_tags "+
+			"INNER JOIN `svc-article`.tags on tags.id = article_tags.tag_id "+
+			"WHERE article_tags.article_id IN ("+placeholders(len(articleIDs))+")", intArgs(articleIDs)...)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetArticleTags", "QueryContext")).
+			Errorf("error selecting article tags because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int
+		var tag string
+		if err := rows.Scan(&articleID, &tag); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetArticleTags", "Scan")).
+				Errorf("failed to read article tags because %v", err)
+			return nil, err
+		}
+		tags[articleID] = append(tags[articleID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (articleRepo *ArticleRepo) CountTagForDateName(name string, date time.Time) (count int, err error) {
+	err = articleRepo.read(func(reader *ArticleRepo) error {
+		count, err = reader.countTagForDateName(name, date)
+		return err
+	})
+	return count, err
+}
+
+func (articleRepo *ArticleRepo) countTagForDateName(name string, date time.Time) (int, error) {
+
+	day := date.Format(DateFormat)
 
 	countStmt, err := articleRepo.db.PrepareContext(articleRepo.ctx,
 		"SELECT count(tags.id) as tag_count "+
 			"FROM tags "+
 			"INNER JOIN article_tags on tags.id = article_tags.tag_id "+
 			"INNER JOIN articles on article_tags.article_id = articles.id "+
-			"WHERE tags.tag_title = ? AND articles.date = ?")
+			"WHERE tags.tag_title = ? AND articles.date = ? AND articles.deleted_at IS NULL "+
+			"AND articles.status = '"+model.StatusPublished+"'")
 
 	if err != nil {
 		articleRepo.logger.
@@ -100,25 +321,36 @@ func (articleRepo *ArticleRepo) CountTagForDateName(name, date string) (int, err
 	}
 
 	var count int
-	err = countStmt.QueryRow(name, date).Scan(&count)
+	err = countStmt.QueryRow(name, day).Scan(&count)
 	if err != nil {
 		articleRepo.logger.
 			WithFields(field.ErrorFields("GetArticleByID", "QueryRow")).
-			Errorf("failed to get count of tag name %s on date %s because %v", name, date, err)
+			Errorf("failed to get count of tag name %s on date %s because %v", name, day, err)
 		return -1, err
 	}
 
 	return count, nil
 }
 
-func (articleRepo *ArticleRepo) GetRelatedTagForDateAndName(name, date string) ([]string, error) {
+func (articleRepo *ArticleRepo) GetRelatedTagForDateAndName(name string, date time.Time) (tags []string, err error) {
+	err = articleRepo.read(func(reader *ArticleRepo) error {
+		tags, err = reader.getRelatedTagForDateAndName(name, date)
+		return err
+	})
+	return tags, err
+}
+
+func (articleRepo *ArticleRepo) getRelatedTagForDateAndName(name string, date time.Time) ([]string, error) {
+
+	day := date.Format(DateFormat)
 
 	relatedTagsStmt, err := articleRepo.db.PrepareContext(articleRepo.ctx,
 		"SELECT DISTINCT tags.tag_title "+
 			"FROM tags "+
 			"INNER JOIN article_tags on tags.id = article_tags.tag_id "+
 			"INNER JOIN articles on article_tags.article_id = articles.id "+
-			"WHERE tag_title != ? AND articles.date = ?")
+			"WHERE tag_title != ? AND articles.date = ? AND articles.deleted_at IS NULL "+
+			"AND articles.status = '"+model.StatusPublished+"'")
 
 	if err != nil {
 		articleRepo.logger.
@@ -127,7 +359,7 @@ func (articleRepo *ArticleRepo) GetRelatedTagForDateAndName(name, date string) (
 		return nil, err
 	}
 
-	rows, err := relatedTagsStmt.Query(name, date)
+	rows, err := relatedTagsStmt.Query(name, day)
 	if err != nil {
 		articleRepo.logger.
 			WithFields(field.ErrorFields("GetRelatedTagForDate", "Query")).
@@ -143,7 +375,7 @@ func (articleRepo *ArticleRepo) GetRelatedTagForDateAndName(name, date string) (
 		if err != nil {
 			articleRepo.logger.
 				WithFields(field.ErrorFields("GetRelatedTagForDate", "Scan")).
-				Errorf("failed to get list of related tags on date %s because %v", date, err)
+				Errorf("failed to get list of related tags on date %s because %v", day, err)
 			return nil, err
 		}
 		relatedTags = append(relatedTags, relatedTag)
@@ -152,7 +384,17 @@ func (articleRepo *ArticleRepo) GetRelatedTagForDateAndName(name, date string) (
 	return relatedTags, nil
 }
 
-func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name, date string) ([]string, error) {
+func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name string, date time.Time) (ids []string, err error) {
+	err = articleRepo.read(func(reader *ArticleRepo) error {
+		ids, err = reader.getArticleIDForDateAndTag(name, date)
+		return err
+	})
+	return ids, err
+}
+
+func (articleRepo *ArticleRepo) getArticleIDForDateAndTag(name string, date time.Time) ([]string, error) {
+
+	day := date.Format(DateFormat)
 
 	taggedArticleStmt, err := articleRepo.db.PrepareContext(articleRepo.ctx,
 		"SELECT articles.id "+
@@ -161,6 +403,8 @@ func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name, date string) ([]
 			"INNER JOIN articles on article_tags.article_id = articles.id "+
 			"WHERE tag_title = ? "+
 			"AND articles.date = ? "+
+			"AND articles.deleted_at IS NULL "+
+			"AND articles.status = '"+model.StatusPublished+"' "+
 			"LIMIT 10")
 
 	if err != nil {
@@ -170,7 +414,7 @@ func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name, date string) ([]
 		return nil, err
 	}
 
-	rows, err := taggedArticleStmt.Query(name, date)
+	rows, err := taggedArticleStmt.Query(name, day)
 	if err != nil {
 		articleRepo.logger.
 			WithFields(field.ErrorFields("GetArticleIDForDateAndTag", "Query")).
@@ -186,7 +430,7 @@ func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name, date string) ([]
 		if err != nil {
 			articleRepo.logger.
 				WithFields(field.ErrorFields("GetArticleIDForDateAndTag", "Scan")).
-				Errorf("failed to get list of tagged articles on date %s for tag %s because %v", date, name, err)
+				Errorf("failed to get list of tagged articles on date %s for tag %s because %v", day, name, err)
 			return nil, err
 		}
 		taggedArticles = append(taggedArticles, articleID)
@@ -195,16 +439,561 @@ func (articleRepo *ArticleRepo) GetArticleIDForDateAndTag(name, date string) ([]
 	return taggedArticles, nil
 }
 
-func (articleRepo *ArticleRepo) CreateArticle(article model.Article, tags []string) (*model.Article, []*model.Tag, error) {
+func (articleRepo *ArticleRepo) CreateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error) {
 
-	tagItems, err := articleRepo.getTagsByName(articleRepo.ctx, tags)
+	tagItems, err := articleRepo.resolveTags(articleRepo.ctx, tags)
 	if err != nil {
 		articleRepo.logger.
-			WithFields(field.ErrorFields("CreateArticle", "getTagsByName")).
-			Infof("error getting tags because %v", err)
+			WithFields(field.ErrorFields("CreateArticle", "resolveTags")).
+			Errorf("error resolving tags because %v", err)
+		return nil, nil, err
+	}
+
+	tagIdList := tagIDList(tagItems)
+
+	article.Version = 1
+	article.UpdatedAt = time.Now().UTC()
+	err = articleRepo.insertArticle(articleRepo.ctx, article)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateArticle", "insertArticle")).
+			Errorf("failed to insert new article %d, because %v", article.Id, err)
+		return nil, nil, err
+	}
+
+	err = articleRepo.insertArticleTags(articleRepo.ctx, article.Id, tagIdList)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateArticle", "insertArticleTags")).
+			Errorf("failed to insert article tag because %v", err)
 		return nil, nil, err
 	}
 
+	err = articleRepo.insertRevision(articleRepo.ctx, model.Revision{
+		ArticleId: article.Id,
+		Revision:  1,
+		Title:     article.Title,
+		Date:      article.Date,
+		Body:      article.Body,
+		Tags:      tagNameList(tagItems),
+		Author:    author,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateArticle", "insertRevision")).
+			Errorf("failed to insert first revision of article %d because %v", article.Id, err)
+		return nil, nil, err
+	}
+
+	return &article, tagItems, nil
+}
+
+// ImportTx writes the batches of an import in a single transaction, none of
+// its articles are kept unless it is committed. Tags new to the import are
+// stored as they are met, as for every other write.
+type ImportTx interface {
+	CreateArticles(articles []model.ArticleImport, author string) error
+	Commit() error
+	Rollback() error
+}
+
+type articleImportTx struct {
+	articleRepo *ArticleRepo
+	tx          *sql.Tx
+}
+
+// BeginImport starts the transaction of an import
+func (articleRepo *ArticleRepo) BeginImport() (ImportTx, error) {
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return nil, err
+	}
+	return &articleImportTx{articleRepo: articleRepo, tx: tx}, nil
+}
+
+func (importTx *articleImportTx) CreateArticles(articles []model.ArticleImport, author string) error {
+	return importTx.articleRepo.insertArticles(importTx.tx, articles, author)
+}
+
+func (importTx *articleImportTx) Commit() error {
+	return importTx.tx.Commit()
+}
+
+func (importTx *articleImportTx) Rollback() error {
+	return importTx.tx.Rollback()
+}
+
+// CreateArticles creates a batch of articles along with their tags, authors
+// and first revisions in a single transaction, inserting several rows per
+// statement. Either every article of the batch is created or none is.
+func (articleRepo *ArticleRepo) CreateArticles(articles []model.ArticleImport, author string) error {
+
+	if len(articles) == 0 {
+		return nil
+	}
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := articleRepo.insertArticles(tx, articles, author); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// insertArticles inserts a batch of articles in tx
+func (articleRepo *ArticleRepo) insertArticles(tx *sql.Tx, articles []model.ArticleImport, author string) error {
+
+	if len(articles) == 0 {
+		return nil
+	}
+
+	var tagNames []string
+	seen := make(map[string]bool)
+	for _, item := range articles {
+		for _, tag := range item.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tagNames = append(tagNames, tag)
+			}
+		}
+	}
+
+	tagItems, err := articleRepo.resolveTags(articleRepo.ctx, tagNames)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateArticles", "resolveTags")).
+			Errorf("error resolving tags because %v", err)
+		return err
+	}
+
+	tagIDs := make(map[string]int)
+	for _, tag := range tagItems {
+		tagIDs[tag.Name] = tag.Id
+	}
+
+	now := time.Now().UTC()
+	var articleRows, tagRows, authorRows, revisionRows []string
+	var articleArgs, tagArgs, authorArgs, revisionArgs []interface{}
+	for _, item := range articles {
+		article := item.Article
+		itemTags := uniqueTags(item.Tags)
+
+		articleRows = append(articleRows, "("+placeholders(8)+")")
+		articleArgs = append(articleArgs, article.Id, article.Title, article.Date, article.Body,
+			article.Status, article.PublishAt, 1, now)
+
+		for _, tag := range itemTags {
+			tagRows = append(tagRows, "(?, ?)")
+			tagArgs = append(tagArgs, article.Id, tagIDs[tag])
+		}
+
+		for _, authorID := range item.AuthorIds {
+			authorRows = append(authorRows, "(?, ?)")
+			authorArgs = append(authorArgs, article.Id, authorID)
+		}
+
+		tags, err := json.Marshal(itemTags)
+		if err != nil {
+			return err
+		}
+		revisionRows = append(revisionRows, "("+placeholders(8)+")")
+		revisionArgs = append(revisionArgs, article.Id, 1, article.Title, article.Date, article.Body,
+			string(tags), author, now)
+	}
+
+	statements := []struct {
+		step  string
+		query string
+		rows  []string
+		args  []interface{}
+	}{
+		{"articles", "INSERT INTO `svc-article`.articles" +
+			"(`id`, `title`, `date`, `body`, `status`, `publish_at`, `version`, `updated_at`) VALUES ",
+			articleRows, articleArgs},
+		{"article_tags", "INSERT INTO `svc-article`.article_tags(article_id, tag_id) VALUES ",
+			tagRows, tagArgs},
+		{"article_authors", "INSERT INTO `svc-article`.article_authors(article_id, author_id) VALUES ",
+			authorRows, authorArgs},
+		{"article_revisions", "INSERT INTO `svc-article`.article_revisions" +
+			"(`article_id`, `revision`, `title`, `date`, `body`, `tags`, `author`, `created_at`) VALUES ",
+			revisionRows, revisionArgs},
+	}
+
+	for _, statement := range statements {
+		if len(statement.rows) == 0 {
+			continue
+		}
+
+		_, err = tx.ExecContext(articleRepo.ctx, statement.query+strings.Join(statement.rows, ", "), statement.args...)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("CreateArticles", "ExecContext")).
+				Errorf("failed to insert %d rows into %s because %v", len(statement.rows), statement.step, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateArticle stores a new version of the article, an article.Version
+// above 0 must still be the latest version or ErrVersionConflict is returned.
+func (articleRepo *ArticleRepo) UpdateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error) {
+
+	tagItems, err := articleRepo.resolveTags(articleRepo.ctx, tags)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "resolveTags")).
+			Errorf("error resolving tags because %v", err)
+		return nil, nil, err
+	}
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRowContext(articleRepo.ctx,
+		"SELECT `version` FROM `svc-article`.articles WHERE `id` = ? AND `deleted_at` IS NULL FOR UPDATE", article.Id).Scan(&version)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "QueryRowContext")).
+			Errorf("failed to lock article %d because %v", article.Id, err)
+		return nil, nil, err
+	}
+
+	if article.Version > 0 && article.Version != version {
+		return nil, nil, ErrVersionConflict
+	}
+	article.Version = version + 1
+	article.UpdatedAt = time.Now().UTC()
+
+	var revision int
+	err = tx.QueryRowContext(articleRepo.ctx,
+		"SELECT COALESCE(MAX(`revision`), 0) FROM `svc-article`.article_revisions WHERE `article_id` = ?",
+		article.Id).Scan(&revision)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "QueryRowContext")).
+			Errorf("failed to get latest revision of article %d because %v", article.Id, err)
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.articles "+
+			"SET `title` = ?, `date` = ?, `body` = ?, `status` = ?, `publish_at` = ?, `version` = ?, `updated_at` = ? "+
+			"WHERE `id` = ?",
+		article.Title, article.Date, article.Body, article.Status, article.PublishAt, article.Version,
+		article.UpdatedAt, article.Id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "ExecContext")).
+			Errorf("error executing update article statement: %v", err)
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"DELETE FROM `svc-article`.article_tags WHERE `article_id` = ?", article.Id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "ExecContext")).
+			Errorf("error clearing article tags: %v", err)
+		return nil, nil, err
+	}
+
+	for _, tagID := range tagIDList(tagItems) {
+		_, err = tx.ExecContext(articleRepo.ctx,
+			"INSERT INTO `svc-article`.article_tags(article_id, tag_id) VALUES (?, ?)", article.Id, tagID)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("UpdateArticle", "ExecContext")).
+				Errorf("error executing insert article tags statement: %v", err)
+			return nil, nil, err
+		}
+	}
+
+	err = insertRevisionTx(articleRepo.ctx, tx, model.Revision{
+		ArticleId: article.Id,
+		Revision:  revision + 1,
+		Title:     article.Title,
+		Date:      article.Date,
+		Body:      article.Body,
+		Tags:      tagNameList(tagItems),
+		Author:    author,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateArticle", "insertRevisionTx")).
+			Errorf("failed to insert revision of article %d because %v", article.Id, err)
+		return nil, nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return nil, nil, err
+	}
+
+	return &article, tagItems, nil
+}
+
+func (articleRepo *ArticleRepo) DeleteArticle(id string) error {
+
+	result, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.articles SET `deleted_at` = ? WHERE `id` = ? AND `deleted_at` IS NULL",
+		time.Now().UTC(), id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("DeleteArticle", "ExecContext")).
+			Errorf("error executing delete article statement: %v", err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (articleRepo *ArticleRepo) RestoreArticle(id string) error {
+
+	result, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.articles SET `deleted_at` = NULL WHERE `id` = ? AND `deleted_at` IS NOT NULL", id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("RestoreArticle", "ExecContext")).
+			Errorf("error executing restore article statement: %v", err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (articleRepo *ArticleRepo) GetDeletedArticles() ([]*model.Article, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `title`, `date`, `body`, `deleted_at` "+
+			"FROM `svc-article`.articles "+
+			"WHERE `deleted_at` IS NOT NULL "+
+			"ORDER BY `deleted_at` DESC")
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetDeletedArticles", "QueryContext")).
+			Errorf("error selecting deleted articles because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []*model.Article
+	for rows.Next() {
+		var article model.Article
+		err := rows.Scan(&article.Id, &article.Title, &article.Date, &article.Body, &article.DeletedAt)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetDeletedArticles", "Scan")).
+				Errorf("failed to read deleted articles because %v", err)
+			return nil, err
+		}
+		articles = append(articles, &article)
+	}
+
+	return articles, rows.Err()
+}
+
+// PurgeArticles permanently removes articles soft deleted before the given time
+// along with their tags and revisions, returning the number of articles removed.
+func (articleRepo *ArticleRepo) PurgeArticles(deletedBefore time.Time) (int, error) {
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	purged := "SELECT `id` FROM `svc-article`.articles WHERE `deleted_at` IS NOT NULL AND `deleted_at` < ?"
+	statements := []string{
+		"DELETE FROM `svc-article`.article_tags WHERE `article_id` IN (" + purged + ")",
+		"DELETE FROM `svc-article`.article_revisions WHERE `article_id` IN (" + purged + ")",
+		"DELETE FROM `svc-article`.article_authors WHERE `article_id` IN (" + purged + ")",
+	}
+	for _, statement := range statements {
+		_, err = tx.ExecContext(articleRepo.ctx, statement, deletedBefore)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("PurgeArticles", "ExecContext")).
+				Errorf("error purging article references because: %v", err)
+			return -1, err
+		}
+	}
+
+	result, err := tx.ExecContext(articleRepo.ctx,
+		"DELETE FROM `svc-article`.articles WHERE `deleted_at` IS NOT NULL AND `deleted_at` < ?", deletedBefore)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("PurgeArticles", "ExecContext")).
+			Errorf("error purging articles because: %v", err)
+		return -1, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		articleRepo.logger.Errorf("error counting purged articles: %v", err)
+		return -1, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return -1, err
+	}
+
+	return int(count), nil
+}
+
+// PublishScheduledArticles promotes every scheduled article whose publish time
+// has passed and returns the ids of the promoted articles. Like any other
+// change, the promotion bumps the version and update time of the articles.
+func (articleRepo *ArticleRepo) PublishScheduledArticles(now time.Time) ([]int, error) {
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(articleRepo.ctx,
+		"SELECT `id` FROM `svc-article`.articles "+
+			"WHERE `status` = ? AND `publish_at` <= ? AND `deleted_at` IS NULL "+
+			"FOR UPDATE", model.StatusScheduled, now)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("PublishScheduledArticles", "QueryContext")).
+			Errorf("error selecting scheduled articles because: %v", err)
+		return nil, err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			articleRepo.logger.
+				WithFields(field.ErrorFields("PublishScheduledArticles", "Scan")).
+				Errorf("failed to read scheduled articles because %v", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.articles SET `status` = ?, `version` = `version` + 1, `updated_at` = ? "+
+			"WHERE `status` = ? AND `publish_at` <= ? AND `deleted_at` IS NULL",
+		model.StatusPublished, now, model.StatusScheduled, now)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("PublishScheduledArticles", "ExecContext")).
+			Errorf("error publishing scheduled articles because: %v", err)
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (articleRepo *ArticleRepo) GetRevisions(articleID string) ([]*model.Revision, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `article_id`, `revision`, `title`, `date`, `body`, `tags`, `author`, `created_at` "+
+			"FROM `svc-article`.article_revisions "+
+			"WHERE `article_id` = ? "+
+			"ORDER BY `revision`", articleID)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetRevisions", "QueryContext")).
+			Errorf("error selecting revisions because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*model.Revision
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetRevisions", "Scan")).
+				Errorf("failed to read revisions of article %s because %v", articleID, err)
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (articleRepo *ArticleRepo) GetRevision(articleID string, revision int) (*model.Revision, error) {
+
+	row := articleRepo.db.QueryRowContext(articleRepo.ctx,
+		"SELECT `id`, `article_id`, `revision`, `title`, `date`, `body`, `tags`, `author`, `created_at` "+
+			"FROM `svc-article`.article_revisions "+
+			"WHERE `article_id` = ? AND `revision` = ?", articleID, revision)
+
+	result, err := scanRevision(row)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetRevision", "Scan")).
+			Errorf("failed to query revision %d of article %s because %v", revision, articleID, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// resolveTags returns the tags with the given names, inserting any that do not exist yet
+func (articleRepo *ArticleRepo) resolveTags(ctx context.Context, tags []string) ([]*model.Tag, error) {
+
+	tagItems, err := articleRepo.getTagsByName(ctx, tags)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("resolveTags", "getTagsByName")).
+			Infof("error getting tags because %v", err)
+		return nil, err
+	}
+
 	var tagMap = make(map[string]int)
 	for _, tag := range tags {
 		tagMap[tag] = -1
@@ -217,12 +1006,12 @@ func (articleRepo *ArticleRepo) CreateArticle(article model.Article, tags []stri
 	for tagName, id := range tagMap {
 		if id == -1 {
 
-			newTagId, err := articleRepo.insertTag(articleRepo.ctx, tagName)
+			newTagId, err := articleRepo.insertTag(ctx, tagName)
 			if err != nil {
 				articleRepo.logger.
-					WithFields(field.ErrorFields("CreateArticle", "insertTag")).
+					WithFields(field.ErrorFields("resolveTags", "insertTag")).
 					Errorf("failed to insert new tag %s, because %v", tagName, err)
-				return nil, nil, err
+				return nil, err
 			}
 
 			tagMap[tagName] = newTagId
@@ -231,25 +1020,7 @@ func (articleRepo *ArticleRepo) CreateArticle(article model.Article, tags []stri
 		}
 	}
 
-	tagIdList := tagIDList(tagItems)
-
-	err = articleRepo.insertArticle(articleRepo.ctx, article)
-	if err != nil {
-		articleRepo.logger.
-			WithFields(field.ErrorFields("CreateArticle", "insertArticle")).
-			Errorf("failed to insert new article %d, because %v", article.Id, err)
-		return nil, nil, err
-	}
-
-	err = articleRepo.insertArticleTags(articleRepo.ctx, article.Id, tagIdList)
-	if err != nil {
-		articleRepo.logger.
-			WithFields(field.ErrorFields("CreateArticle", "insertArticleTags")).
-			Errorf("failed to insert article tag because %v", err)
-		return nil, nil, err
-	}
-
-	return &article, tagItems, nil
+	return tagItems, nil
 }
 
 func (articleRepo *ArticleRepo) getTagsByName(ctx context.Context, tagNames []string) ([]*model.Tag, error) {
@@ -269,6 +1040,7 @@ func (articleRepo *ArticleRepo) getTagsByName(ctx context.Context, tagNames []st
 		err = statement.QueryRow(name).Scan(&tag.Id, &tag.Name)
 		if err == sql.ErrNoRows {
 			articleRepo.logger.Infof("no tags found with names %s", name)
+			continue
 		} else if err != nil {
 			articleRepo.logger.
 				WithFields(field.ErrorFields("getTagsByName", "Scan")).
@@ -388,14 +1160,16 @@ func (articleRepo *ArticleRepo) insertArticle(ctx context.Context, article model
 	defer tx.Rollback()
 
 	insertArticleStmt, err := tx.PrepareContext(ctx,
-		"INSERT INTO `svc-article`.articles(`id`, `title`, `date`, `body`) VALUES(?, ?, ?, ?)")
+		"INSERT INTO `svc-article`.articles(`id`, `title`, `date`, `body`, `status`, `publish_at`, `version`, `updated_at`) "+
+			"VALUES(?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		articleRepo.logger.Errorf("article statement creation failed because: %v", err)
 		return err
 	}
 	defer insertArticleStmt.Close()
 
-	result, err := insertArticleStmt.Exec(article.Id, article.Title, article.Date, article.Body)
+	result, err := insertArticleStmt.Exec(article.Id, article.Title, article.Date, article.Body,
+		article.Status, article.PublishAt, article.Version, article.UpdatedAt)
 	if err != nil {
 		articleRepo.logger.Errorf("error executing insert article statement: %v", err)
 		return err
@@ -449,6 +1223,102 @@ func (articleRepo *ArticleRepo) insertArticleTags(ctx context.Context, articleID
 	return nil
 }
 
+func (articleRepo *ArticleRepo) insertRevision(ctx context.Context, revision model.Revision) error {
+
+	tx, err := articleRepo.db.Begin()
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = insertRevisionTx(ctx, tx, revision)
+	if err != nil {
+		articleRepo.logger.Errorf("error executing insert revision statement: %v", err)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func insertRevisionTx(ctx context.Context, tx *sql.Tx, revision model.Revision) error {
+
+	tags, err := json.Marshal(revision.Tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO `svc-article`.article_revisions"+
+			"(`article_id`, `revision`, `title`, `date`, `body`, `tags`, `author`, `created_at`) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		revision.ArticleId, revision.Revision, revision.Title, revision.Date, revision.Body,
+		string(tags), revision.Author, revision.CreatedAt)
+
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRevision(row rowScanner) (*model.Revision, error) {
+	var revision model.Revision
+	var tags string
+	err := row.Scan(&revision.Id, &revision.ArticleId, &revision.Revision, &revision.Title,
+		&revision.Date, &revision.Body, &tags, &revision.Author, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &revision.Tags); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// placeholders returns a comma separated list of n query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func intArgs(values []int) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// uniqueTags returns the tags without the repeated ones, an article carries a
+// tag once
+func uniqueTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func tagNameList(tags []*model.Tag) []string {
+	var list []string
+	for _, tag := range tags {
+		list = append(list, tag.Name)
+	}
+	return list
+}
+
 func tagIDList(tags []*model.Tag) []int {
 	var list []int
 	for _, tag := range tags {
@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"rest-article/database/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordedExec is a statement run by a recordingConn
+type recordedExec struct {
+	query string
+	args  []driver.NamedValue
+}
+
+// recordingConn answers every query with a single id column holding ids and
+// records the statements it runs, committed or not
+type recordingConn struct {
+	ids   []int64
+	execs []recordedExec
+}
+
+func (conn *recordingConn) Connect(context.Context) (driver.Conn, error) { return conn, nil }
+func (conn *recordingConn) Driver() driver.Driver                        { return nil }
+func (conn *recordingConn) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (conn *recordingConn) Close() error                                 { return nil }
+func (conn *recordingConn) Begin() (driver.Tx, error)                    { return conn, nil }
+func (conn *recordingConn) Commit() error                                { return nil }
+func (conn *recordingConn) Rollback() error                              { return nil }
+
+func (conn *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	conn.execs = append(conn.execs, recordedExec{query, args})
+	return driver.RowsAffected(len(conn.ids)), nil
+}
+
+func (conn *recordingConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &idRows{ids: conn.ids}, nil
+}
+
+type idRows struct {
+	ids []int64
+}
+
+func (rows *idRows) Columns() []string { return []string{"id"} }
+func (rows *idRows) Close() error      { return nil }
+
+func (rows *idRows) Next(dest []driver.Value) error {
+	if len(rows.ids) == 0 {
+		return io.EOF
+	}
+	dest[0], rows.ids = rows.ids[0], rows.ids[1:]
+	return nil
+}
+
+func TestPublishScheduledArticlesBumpsVersion(t *testing.T) {
+	conn := &recordingConn{ids: []int64{3, 5}}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+	articleRepo := NewArticleRepo(context.Background(), db)
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	ids, err := articleRepo.PublishScheduledArticles(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5}, ids)
+
+	if assert.Len(t, conn.execs, 1) {
+		update := conn.execs[0]
+		assert.True(t, strings.Contains(update.query, "`version` = `version` + 1"), update.query)
+		assert.True(t, strings.Contains(update.query, "`updated_at` = ?"), update.query)
+
+		var args []driver.Value
+		for _, arg := range update.args {
+			args = append(args, arg.Value)
+		}
+		assert.Equal(t, []driver.Value{model.StatusPublished, now, model.StatusScheduled, now}, args)
+	}
+}
@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"database/sql"
+	"fmt"
+	"rest-article/database/model"
+	"rest-article/field"
+)
+
+func (articleRepo *ArticleRepo) GetAuthors() ([]*model.Author, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `name`, COALESCE(`email`, ''), COALESCE(`bio`, '') FROM `svc-article`.authors ORDER BY `id`")
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetAuthors", "QueryContext")).
+			Errorf("error selecting authors because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []*model.Author
+	for rows.Next() {
+		var author model.Author
+		if err := rows.Scan(&author.Id, &author.Name, &author.Email, &author.Bio); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetAuthors", "Scan")).
+				Errorf("failed to read authors because %v", err)
+			return nil, err
+		}
+		authors = append(authors, &author)
+	}
+
+	return authors, rows.Err()
+}
+
+func (articleRepo *ArticleRepo) GetAuthorByID(id string) (*model.Author, error) {
+
+	var author model.Author
+	err := articleRepo.db.QueryRowContext(articleRepo.ctx,
+		"SELECT `id`, `name`, COALESCE(`email`, ''), COALESCE(`bio`, '') FROM `svc-article`.authors WHERE `id` = ?", id).
+		Scan(&author.Id, &author.Name, &author.Email, &author.Bio)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetAuthorByID", "QueryRowContext")).
+			Errorf("failed to query author id %s because %v", id, err)
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+func (articleRepo *ArticleRepo) CreateAuthor(author model.Author) (*model.Author, error) {
+
+	result, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"INSERT INTO `svc-article`.authors(`name`, `email`, `bio`) VALUES (?, ?, ?)",
+		author.Name, nullString(author.Email), nullString(author.Bio))
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("CreateAuthor", "ExecContext")).
+			Errorf("error executing insert author statement: %v", err)
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		articleRepo.logger.Errorf("error retrieving author ID: %v", err)
+		return nil, err
+	}
+	author.Id = int(id)
+
+	return &author, nil
+}
+
+func (articleRepo *ArticleRepo) UpdateAuthor(author model.Author) (*model.Author, error) {
+
+	if _, err := articleRepo.GetAuthorByID(fmt.Sprintf("%d", author.Id)); err != nil {
+		return nil, err
+	}
+
+	_, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.authors SET `name` = ?, `email` = ?, `bio` = ? WHERE `id` = ?",
+		author.Name, nullString(author.Email), nullString(author.Bio), author.Id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("UpdateAuthor", "ExecContext")).
+			Errorf("error executing update author statement: %v", err)
+		return nil, err
+	}
+
+	return &author, nil
+}
+
+// DeleteAuthor removes the author and unlinks them from all of their articles
+func (articleRepo *ArticleRepo) DeleteAuthor(id string) error {
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"DELETE FROM `svc-article`.article_authors WHERE `author_id` = ?", id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("DeleteAuthor", "ExecContext")).
+			Errorf("error unlinking author articles because: %v", err)
+		return err
+	}
+
+	result, err := tx.ExecContext(articleRepo.ctx,
+		"DELETE FROM `svc-article`.authors WHERE `id` = ?", id)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("DeleteAuthor", "ExecContext")).
+			Errorf("error executing delete author statement: %v", err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		return sql.ErrNoRows
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetArticleAuthors returns the authors of each of the given articles
+func (articleRepo *ArticleRepo) GetArticleAuthors(articleIDs []int) (map[int][]*model.Author, error) {
+
+	authors := make(map[int][]*model.Author)
+	if len(articleIDs) == 0 {
+		return authors, nil
+	}
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT article_authors.article_id, authors.id, authors.name, "+
+			"COALESCE(authors.email, ''), COALESCE(authors.bio, '') "+
+			"FROM `svc-article`.article_authors "+
+			"INNER JOIN `svc-article`.authors on authors.id = article_authors.author_id "+
+			"WHERE article_authors.article_id IN ("+placeholders(len(articleIDs))+") "+
+			"ORDER BY authors.id", intArgs(articleIDs)...)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetArticleAuthors", "QueryContext")).
+			Errorf("error selecting article authors because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int
+		var author model.Author
+		if err := rows.Scan(&articleID, &author.Id, &author.Name, &author.Email, &author.Bio); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetArticleAuthors", "Scan")).
+				Errorf("failed to read article authors because %v", err)
+			return nil, err
+		}
+		authors[articleID] = append(authors[articleID], &author)
+	}
+
+	return authors, rows.Err()
+}
+
+// SetArticleAuthors replaces the authors linked to the article
+func (articleRepo *ArticleRepo) SetArticleAuthors(articleID int, authorIDs []int) error {
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"DELETE FROM `svc-article`.article_authors WHERE `article_id` = ?", articleID)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("SetArticleAuthors", "ExecContext")).
+			Errorf("error clearing article authors because: %v", err)
+		return err
+	}
+
+	for _, authorID := range authorIDs {
+		_, err = tx.ExecContext(articleRepo.ctx,
+			"INSERT INTO `svc-article`.article_authors(article_id, author_id) VALUES (?, ?)", articleID, authorID)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("SetArticleAuthors", "ExecContext")).
+				Errorf("error linking author %d to article %d because: %v", authorID, articleID, err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
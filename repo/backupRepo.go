@@ -0,0 +1,197 @@
+package repo
+
+import (
+	"fmt"
+	"rest-article/database/model"
+	"rest-article/field"
+)
+
+// DumpArticles returns a page of every stored article, drafts and soft deleted
+// ones included, along with their tags, authors and revisions. Pages are
+// ordered by id and start after afterID.
+func (articleRepo *ArticleRepo) DumpArticles(afterID int, limit int) ([]*model.ArticleDump, error) {
+
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `title`, `date`, `body`, `status`, `publish_at`, `deleted_at`, `version`, `updated_at` "+
+			"FROM `svc-article`.articles "+
+			"WHERE `id` > ? "+
+			"ORDER BY `id` LIMIT ?", afterID, limit)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("DumpArticles", "QueryContext")).
+			Errorf("error selecting articles because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dumps []*model.ArticleDump
+	var ids []int
+	for rows.Next() {
+		var article model.Article
+		err := rows.Scan(&article.Id, &article.Title, &article.Date, &article.Body, &article.Status,
+			&article.PublishAt, &article.DeletedAt, &article.Version, &article.UpdatedAt)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("DumpArticles", "Scan")).
+				Errorf("failed to read articles because %v", err)
+			return nil, err
+		}
+		dumps = append(dumps, &model.ArticleDump{Article: article})
+		ids = append(ids, article.Id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(dumps) == 0 {
+		return dumps, nil
+	}
+
+	tags, err := articleRepo.GetArticleTags(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := articleRepo.GetArticleAuthors(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := articleRepo.getArticleRevisions(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dump := range dumps {
+		dump.Tags = tags[dump.Article.Id]
+		for _, author := range authors[dump.Article.Id] {
+			dump.AuthorIds = append(dump.AuthorIds, author.Id)
+		}
+		dump.Revisions = revisions[dump.Article.Id]
+	}
+
+	return dumps, nil
+}
+
+// getArticleRevisions returns the revisions of each of the articles, oldest first
+func (articleRepo *ArticleRepo) getArticleRevisions(articleIDs []int) (map[int][]*model.Revision, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT `id`, `article_id`, `revision`, `title`, `date`, `body`, `tags`, `author`, `created_at` "+
+			"FROM `svc-article`.article_revisions "+
+			"WHERE `article_id` IN ("+placeholders(len(articleIDs))+") "+
+			"ORDER BY `article_id`, `revision`", intArgs(articleIDs)...)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("getArticleRevisions", "QueryContext")).
+			Errorf("error selecting revisions because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make(map[int][]*model.Revision)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("getArticleRevisions", "Scan")).
+				Errorf("failed to read revisions because %v", err)
+			return nil, err
+		}
+		revisions[revision.ArticleId] = append(revisions[revision.ArticleId], revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// LoadAuthor stores the author as it was dumped, keeping its id
+func (articleRepo *ArticleRepo) LoadAuthor(author model.Author) error {
+
+	_, err := articleRepo.db.ExecContext(articleRepo.ctx,
+		"INSERT INTO `svc-article`.authors(`id`, `name`, `email`, `bio`) VALUES (?, ?, ?, ?)",
+		author.Id, author.Name, nullString(author.Email), nullString(author.Bio))
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("LoadAuthor", "ExecContext")).
+			Errorf("error loading author %d because: %v", author.Id, err)
+		return err
+	}
+
+	return nil
+}
+
+// LoadArticle stores the article as it was dumped, keeping its id, version,
+// update and deletion times along with its tags, authors and revisions. It is
+// written in a single transaction.
+func (articleRepo *ArticleRepo) LoadArticle(dump model.ArticleDump) error {
+
+	tagItems, err := articleRepo.resolveTags(articleRepo.ctx, dump.Tags)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("LoadArticle", "resolveTags")).
+			Errorf("error resolving tags because %v", err)
+		return err
+	}
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	article := dump.Article
+	_, err = tx.ExecContext(articleRepo.ctx,
+		"INSERT INTO `svc-article`.articles"+
+			"(`id`, `title`, `date`, `body`, `status`, `publish_at`, `deleted_at`, `version`, `updated_at`) "+
+			"VALUES ("+placeholders(9)+")",
+		article.Id, article.Title, article.Date, article.Body, article.Status,
+		article.PublishAt, article.DeletedAt, article.Version, article.UpdatedAt)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("LoadArticle", "ExecContext")).
+			Errorf("failed to load article %d because %v", article.Id, err)
+		return err
+	}
+
+	links := []struct {
+		table string
+		ids   []int
+	}{
+		{"article_tags(article_id, tag_id)", tagIDList(tagItems)},
+		{"article_authors(article_id, author_id)", dump.AuthorIds},
+	}
+	for _, link := range links {
+		for _, id := range link.ids {
+			_, err = tx.ExecContext(articleRepo.ctx,
+				"INSERT INTO `svc-article`."+link.table+" VALUES (?, ?)", article.Id, id)
+			if err != nil {
+				articleRepo.logger.
+					WithFields(field.ErrorFields("LoadArticle", "ExecContext")).
+					Errorf("failed to link %d to article %d because %v", id, article.Id, err)
+				return err
+			}
+		}
+	}
+
+	for _, revision := range dump.Revisions {
+		revision.ArticleId = article.Id
+		if err := insertRevisionTx(articleRepo.ctx, tx, *revision); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("LoadArticle", "insertRevisionTx")).
+				Errorf("failed to load revision %d of article %d because %v", revision.Revision, article.Id, err)
+			return fmt.Errorf("revision %d: %v", revision.Revision, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return err
+	}
+
+	return nil
+}
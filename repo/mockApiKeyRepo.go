@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"database/sql"
+	"rest-article/database/model"
+	"time"
+)
+
+// MockAPIKey is the only key known to the mock, it has every article scope and
+// the editor role
+const MockAPIKey = "ra_test"
+
+func (mr *ArticleRepoMock) GetAPIKeys() ([]*model.APIKey, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	return []*model.APIKey{mockAPIKey()}, nil
+}
+
+func (mr *ArticleRepoMock) GetAPIKeyByHash(hash string) (*model.APIKey, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	key := mockAPIKey()
+	if hash != key.Hash {
+		return nil, sql.ErrNoRows
+	}
+
+	return key, nil
+}
+
+func (mr *ArticleRepoMock) CreateAPIKey(key model.APIKey) (*model.APIKey, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	key.Id = 2
+
+	return &key, nil
+}
+
+func (mr *ArticleRepoMock) RevokeAPIKey(id string) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
+
+func mockAPIKey() *model.APIKey {
+	return &model.APIKey{
+		Id:   1,
+		Name: "test key",
+		// sha256 of MockAPIKey
+		Hash:      "70d0b4ac8f3681e7078d75649dbc1069f661e02d027fcadeda5fe37d4d13bf31",
+		Role:      "editor",
+		Scopes:    []string{"articles:read", "articles:write"},
+		CreatedAt: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
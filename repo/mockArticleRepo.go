@@ -2,8 +2,10 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"rest-article/database/model"
+	"strconv"
 	"time"
 )
 
@@ -18,17 +20,38 @@ func (mr *ArticleRepoMock) NewMockArticleRepo(err error) Repo {
 	return mockRepo
 }
 
+// Primary returns the mock itself, it has no replicas
+func (mr *ArticleRepoMock) Primary() Repo {
+	return mr
+}
+
+// MockDeletedArticleID is the id of a soft deleted article, the mock finds
+// every other id
+const MockDeletedArticleID = "404"
+
+// every mock article is at the same version
+const mockArticleVersion = 1
+
+var mockArticleUpdatedAt = time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+
 func (mr *ArticleRepoMock) GetArticleByID(id string) (*model.Article, []*model.Tag, error) {
 
 	if mr.Err != nil {
 		return nil, nil, mr.Err
 	}
 
+	if id == MockDeletedArticleID {
+		return nil, nil, sql.ErrNoRows
+	}
+
 	article := &model.Article{
-		Id:    1,
-		Title: "test article",
-		Date:  time.Now(),
-		Body:  "test article",
+		Id:        1,
+		Title:     "test article",
+		Date:      time.Now(),
+		Body:      "test article",
+		Status:    model.StatusPublished,
+		Version:   mockArticleVersion,
+		UpdatedAt: mockArticleUpdatedAt,
 	}
 
 	tags := []*model.Tag{
@@ -39,7 +62,86 @@ func (mr *ArticleRepoMock) GetArticleByID(id string) (*model.Article, []*model.T
 	return article, tags, nil
 }
 
-func (mr *ArticleRepoMock) CountTagForDateName(name, date string) (int, error) {
+func (mr *ArticleRepoMock) ListArticles(filter model.ArticleFilter) ([]*model.Article, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	var articles []*model.Article
+	for id := 1; id <= 3; id++ {
+		articles = append(articles, &model.Article{
+			Id:        id,
+			Title:     fmt.Sprintf("test article %d", id),
+			Date:      time.Date(2020, 2, id, 0, 0, 0, 0, time.UTC),
+			Body:      "test article",
+			Status:    model.StatusPublished,
+			Version:   mockArticleVersion,
+			UpdatedAt: time.Date(2020, 2, id, 0, 0, 0, 0, time.UTC),
+		})
+	}
+
+	return articles, nil
+}
+
+func (mr *ArticleRepoMock) GetArticleTags(articleIDs []int) (map[int][]string, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	tags := make(map[int][]string)
+	for _, id := range articleIDs {
+		tags[id] = []string{"test", "test2"}
+	}
+
+	return tags, nil
+}
+
+func (mr *ArticleRepoMock) GetAdminArticleByID(id string) (*model.Article, []*model.Tag, error) {
+
+	if mr.Err != nil {
+		return nil, nil, mr.Err
+	}
+
+	if id == MockDeletedArticleID {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	articleID, _ := strconv.Atoi(id)
+	article := &model.Article{
+		Id:        articleID,
+		Title:     "test article",
+		Date:      time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+		Body:      "test article",
+		Status:    model.StatusPublished,
+		Version:   mockArticleVersion,
+		UpdatedAt: mockArticleUpdatedAt,
+	}
+
+	// even ids are scheduled so the admin view has something unpublished to show
+	if articleID%2 == 0 {
+		publishAt := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+		article.Status = model.StatusScheduled
+		article.PublishAt = &publishAt
+	}
+
+	tags := []*model.Tag{
+		{Id: 1, Name: "test"},
+	}
+
+	return article, tags, nil
+}
+
+func (mr *ArticleRepoMock) PublishScheduledArticles(now time.Time) ([]int, error) {
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	return []int{1}, nil
+}
+
+func (mr *ArticleRepoMock) CountTagForDateName(name string, date time.Time) (int, error) {
 
 	if mr.Err != nil {
 		return -1, mr.Err
@@ -48,7 +150,7 @@ func (mr *ArticleRepoMock) CountTagForDateName(name, date string) (int, error) {
 	return 999, nil
 }
 
-func (mr *ArticleRepoMock) GetRelatedTagForDateAndName(name, date string) ([]string, error) {
+func (mr *ArticleRepoMock) GetRelatedTagForDateAndName(name string, date time.Time) ([]string, error) {
 
 	if mr.Err != nil {
 		return nil, mr.Err
@@ -57,19 +159,91 @@ func (mr *ArticleRepoMock) GetRelatedTagForDateAndName(name, date string) ([]str
 	return []string{"test", "test2"}, nil
 }
 
-func (mr *ArticleRepoMock) GetArticleIDForDateAndTag(name, date string) ([]string, error) {
+func (mr *ArticleRepoMock) GetArticleIDForDateAndTag(name string, date time.Time) ([]string, error) {
 	if mr.Err != nil {
 		return nil, mr.Err
 	}
 	return []string{"1", "2", "3", "4"}, nil
 }
 
-func (mr *ArticleRepoMock) CreateArticle(article model.Article, tags []string) (*model.Article, []*model.Tag, error) {
+func (mr *ArticleRepoMock) CreateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error) {
+
+	if mr.Err != nil {
+		return nil, nil, mr.Err
+	}
+
+	var tagItems []*model.Tag
+	for i, tag := range tags {
+		tagItems = append(tagItems, &model.Tag{Id: i, Name: tag})
+	}
+
+	return &article, tagItems, nil
+}
+
+// CreateArticles fails the whole batch when it holds one of the mock articles
+// 1 to 3, as they exist already
+func (mr *ArticleRepoMock) CreateArticles(articles []model.ArticleImport, author string) error {
+
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	for _, item := range articles {
+		if item.Article.Id >= 1 && item.Article.Id <= 3 {
+			return fmt.Errorf("Error 1062: Duplicate entry '%d' for key 'PRIMARY'", item.Article.Id)
+		}
+
+		// an article carries a tag once, as the article_tags key demands
+		seen := make(map[string]bool)
+		for _, tag := range item.Tags {
+			if seen[tag] {
+				return fmt.Errorf("Error 1062: Duplicate entry '%d-%s' for key 'PRIMARY'", item.Article.Id, tag)
+			}
+			seen[tag] = true
+		}
+	}
+
+	return nil
+}
+
+// mockImportTx writes through the mock, it keeps nothing to roll back
+type mockImportTx struct {
+	mr *ArticleRepoMock
+}
+
+func (mr *ArticleRepoMock) BeginImport() (ImportTx, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	return &mockImportTx{mr: mr}, nil
+}
+
+func (importTx *mockImportTx) CreateArticles(articles []model.ArticleImport, author string) error {
+	return importTx.mr.CreateArticles(articles, author)
+}
+
+func (importTx *mockImportTx) Commit() error {
+	return nil
+}
+
+func (importTx *mockImportTx) Rollback() error {
+	return nil
+}
+
+func (mr *ArticleRepoMock) UpdateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error) {
 
 	if mr.Err != nil {
 		return nil, nil, mr.Err
 	}
 
+	if article.Version > 0 && article.Version != mockArticleVersion {
+		return nil, nil, ErrVersionConflict
+	}
+	article.Version = mockArticleVersion + 1
+	article.UpdatedAt = time.Now().UTC()
+
 	var tagItems []*model.Tag
 	for i, tag := range tags {
 		tagItems = append(tagItems, &model.Tag{Id: i, Name: tag})
@@ -78,6 +252,82 @@ func (mr *ArticleRepoMock) CreateArticle(article model.Article, tags []string) (
 	return &article, tagItems, nil
 }
 
+func (mr *ArticleRepoMock) DeleteArticle(id string) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
+
+func (mr *ArticleRepoMock) RestoreArticle(id string) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
+
+func (mr *ArticleRepoMock) GetDeletedArticles() ([]*model.Article, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	deletedAt := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	return []*model.Article{
+		{
+			Id:        3,
+			Title:     "deleted article",
+			Date:      time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+			Body:      "deleted article",
+			DeletedAt: &deletedAt,
+		},
+	}, nil
+}
+
+func (mr *ArticleRepoMock) PurgeArticles(deletedBefore time.Time) (int, error) {
+	if mr.Err != nil {
+		return -1, mr.Err
+	}
+
+	return 1, nil
+}
+
+func (mr *ArticleRepoMock) GetRevisions(articleID string) ([]*model.Revision, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	first, _ := mr.GetRevision(articleID, 1)
+	second, _ := mr.GetRevision(articleID, 2)
+
+	return []*model.Revision{first, second}, nil
+}
+
+func (mr *ArticleRepoMock) GetRevision(articleID string, revision int) (*model.Revision, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	id, _ := strconv.Atoi(articleID)
+
+	return &model.Revision{
+		Id:        revision,
+		ArticleId: id,
+		Revision:  revision,
+		Title:     fmt.Sprintf("test article revision %d", revision),
+		Date:      time.Date(2020, 2, revision, 0, 0, 0, 0, time.UTC),
+		Body:      "test article",
+		Tags:      []string{"test", fmt.Sprintf("test%d", revision)},
+		Author:    "tester",
+		CreatedAt: time.Date(2020, 2, revision, 12, 0, 0, 0, time.UTC),
+	}, nil
+}
+
 func (mr *ArticleRepoMock) getTagsByName(ctx context.Context, tagNames []string) ([]*model.Tag, error) {
 	if mr.Err != nil {
 		return nil, mr.Err
@@ -136,3 +386,11 @@ func (mr *ArticleRepoMock) insertArticleTags(ctx context.Context, articleID int,
 
 	return nil
 }
+
+func (mr *ArticleRepoMock) insertRevision(ctx context.Context, revision model.Revision) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
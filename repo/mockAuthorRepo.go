@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"fmt"
+	"rest-article/database/model"
+	"strconv"
+)
+
+func (mr *ArticleRepoMock) GetAuthors() ([]*model.Author, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	first, _ := mr.GetAuthorByID("1")
+	second, _ := mr.GetAuthorByID("2")
+
+	return []*model.Author{first, second}, nil
+}
+
+func (mr *ArticleRepoMock) GetAuthorByID(id string) (*model.Author, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	authorID, _ := strconv.Atoi(id)
+
+	return &model.Author{
+		Id:    authorID,
+		Name:  fmt.Sprintf("test author %d", authorID),
+		Email: fmt.Sprintf("author%d@test.com", authorID),
+		Bio:   "test author",
+	}, nil
+}
+
+func (mr *ArticleRepoMock) CreateAuthor(author model.Author) (*model.Author, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	author.Id = 1
+
+	return &author, nil
+}
+
+func (mr *ArticleRepoMock) UpdateAuthor(author model.Author) (*model.Author, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	return &author, nil
+}
+
+func (mr *ArticleRepoMock) DeleteAuthor(id string) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
+
+func (mr *ArticleRepoMock) GetArticleAuthors(articleIDs []int) (map[int][]*model.Author, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	author, _ := mr.GetAuthorByID("1")
+
+	authors := make(map[int][]*model.Author)
+	for _, id := range articleIDs {
+		authors[id] = []*model.Author{author}
+	}
+
+	return authors, nil
+}
+
+func (mr *ArticleRepoMock) SetArticleAuthors(articleID int, authorIDs []int) error {
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"fmt"
+	"rest-article/database/model"
+	"strconv"
+)
+
+// DumpArticles pages through the mock articles 1 to 3, the last of which is
+// a soft deleted draft
+func (mr *ArticleRepoMock) DumpArticles(afterID int, limit int) ([]*model.ArticleDump, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	var dumps []*model.ArticleDump
+	for id := afterID + 1; id <= 3 && len(dumps) < limit; id++ {
+		article, _, _ := mr.GetArticleByID(strconv.Itoa(id))
+		article.Id = id
+		article.Date = mockArticleUpdatedAt
+		if id == 3 {
+			deletedAt := mockArticleUpdatedAt
+			article.Status = model.StatusDraft
+			article.DeletedAt = &deletedAt
+		}
+
+		revisions, _ := mr.GetRevisions(strconv.Itoa(id))
+		dumps = append(dumps, &model.ArticleDump{
+			Article:   *article,
+			Tags:      []string{"test", "test2"},
+			AuthorIds: []int{1},
+			Revisions: revisions,
+		})
+	}
+
+	return dumps, nil
+}
+
+func (mr *ArticleRepoMock) LoadAuthor(author model.Author) error {
+
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	return nil
+}
+
+// LoadArticle fails for the mock articles 1 to 3, as they exist already
+func (mr *ArticleRepoMock) LoadArticle(dump model.ArticleDump) error {
+
+	if mr.Err != nil {
+		return mr.Err
+	}
+
+	if dump.Article.Id >= 1 && dump.Article.Id <= 3 {
+		return fmt.Errorf("Error 1062: Duplicate entry '%d' for key 'PRIMARY'", dump.Article.Id)
+	}
+
+	return nil
+}
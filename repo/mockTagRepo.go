@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"database/sql"
+	"rest-article/database/model"
+)
+
+func (mr *ArticleRepoMock) GetTagCounts() ([]*model.TagCount, error) {
+
+	if mr.Err != nil {
+		return nil, mr.Err
+	}
+
+	return []*model.TagCount{
+		{Id: 1, Name: "test", Count: 3},
+		{Id: 2, Name: "test2", Count: 1},
+	}, nil
+}
+
+// MergeTags knows the mock tags test and test2
+func (mr *ArticleRepoMock) MergeTags(from, into string) (int, error) {
+
+	if mr.Err != nil {
+		return -1, mr.Err
+	}
+
+	switch from {
+	case "test":
+		return 3, nil
+	case "test2":
+		return 1, nil
+	default:
+		return -1, sql.ErrNoRows
+	}
+}
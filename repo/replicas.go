@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"rest-article/log"
+	"sync/atomic"
+	"time"
+)
+
+// Replica is a read only copy of the primary database
+type Replica struct {
+	Name string
+	DB   *sql.DB
+}
+
+// Replicas hands out the replicas that answer in turn. A replica is left out
+// from the moment a read on it fails with a transient error or it does not
+// answer a health check, until it answers one again.
+type Replicas struct {
+	ctx      context.Context
+	replicas []*replica
+	next     uint32
+	logger   *logrus.Entry
+}
+
+type replica struct {
+	Replica
+	healthy int32
+}
+
+// NewReplicas returns the set of replicas, every replica counts as healthy
+// until it is checked
+func NewReplicas(ctx context.Context, replicas []Replica) *Replicas {
+	set := &Replicas{
+		ctx:    ctx,
+		logger: log.NewLogger().WithContext(ctx).WithField("module", "repo"),
+	}
+	for _, r := range replicas {
+		set.replicas = append(set.replicas, &replica{Replica: r, healthy: 1})
+	}
+	return set
+}
+
+// Healthy returns the number of replicas reads currently go to
+func (replicas *Replicas) Healthy() int {
+	healthy := 0
+	for _, r := range replicas.replicas {
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// pick returns the next healthy replica, nil when none is
+func (replicas *Replicas) pick() *sql.DB {
+	count := uint32(len(replicas.replicas))
+	if count == 0 {
+		return nil
+	}
+
+	start := atomic.AddUint32(&replicas.next, 1)
+	for i := uint32(0); i < count; i++ {
+		r := replicas.replicas[(start+i)%count]
+		if atomic.LoadInt32(&r.healthy) == 1 {
+			return r.DB
+		}
+	}
+	return nil
+}
+
+// failed leaves out the replica db until a health check finds it answering
+func (replicas *Replicas) failed(db *sql.DB, err error) {
+	for _, r := range replicas.replicas {
+		if r.DB == db {
+			replicas.setHealthy(r, false, err)
+		}
+	}
+}
+
+func (replicas *Replicas) setHealthy(r *replica, healthy bool, err error) {
+	if healthy {
+		if atomic.SwapInt32(&r.healthy, 1) == 0 {
+			replicas.logger.Infof("Replica %s is answering again", r.Name)
+		}
+		return
+	}
+	if atomic.SwapInt32(&r.healthy, 0) == 1 {
+		replicas.logger.Warnf("Replica %s left out of reads because: %v", r.Name, err)
+	}
+}
+
+// Check pings every replica, each has timeout to answer
+func (replicas *Replicas) Check(timeout time.Duration) {
+	for _, r := range replicas.replicas {
+		ctx, cancel := context.WithTimeout(replicas.ctx, timeout)
+		err := r.DB.PingContext(ctx)
+		cancel()
+		replicas.setHealthy(r, err == nil, err)
+	}
+}
+
+// Run checks the replicas every interval until the context of the set is done
+func (replicas *Replicas) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		replicas.Check(interval)
+
+		select {
+		case <-replicas.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes the connections to every replica
+func (replicas *Replicas) Close() error {
+	var firstErr error
+	for _, r := range replicas.replicas {
+		if err := r.DB.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
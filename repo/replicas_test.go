@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// openDB returns a handle that never connects unless it is used
+func openDB(t *testing.T, addr string) *sql.DB {
+	db, err := sql.Open("mysql", "user:pass@tcp("+addr+")/svc-article")
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReplicasRoundRobin(t *testing.T) {
+	first, second := openDB(t, "127.0.0.1:1"), openDB(t, "127.0.0.1:2")
+	replicas := NewReplicas(context.Background(), []Replica{{"first", first}, {"second", second}})
+
+	picked := map[*sql.DB]int{}
+	for i := 0; i < 4; i++ {
+		picked[replicas.pick()]++
+	}
+	assert.Equal(t, map[*sql.DB]int{first: 2, second: 2}, picked)
+
+	replicas.failed(first, driver.ErrBadConn)
+	assert.Equal(t, 1, replicas.Healthy())
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, second, replicas.pick())
+	}
+
+	replicas.failed(second, driver.ErrBadConn)
+	assert.Nil(t, replicas.pick())
+}
+
+func TestReplicasCheck(t *testing.T) {
+	// nothing listens on port 1, the ping is refused
+	replicas := NewReplicas(context.Background(), []Replica{{"down", openDB(t, "127.0.0.1:1")}})
+	assert.Equal(t, 1, replicas.Healthy())
+
+	replicas.Check(time.Second)
+	assert.Equal(t, 0, replicas.Healthy())
+	assert.Nil(t, replicas.pick())
+}
+
+func TestArticleRepoRead(t *testing.T) {
+	primary, replica := openDB(t, "127.0.0.1:1"), openDB(t, "127.0.0.1:2")
+	replicas := NewReplicas(context.Background(), []Replica{{"replica", replica}})
+	articleRepo := NewReplicatedArticleRepo(context.Background(), primary, replicas).(*ArticleRepo)
+
+	// readFrom returns the db the read was last run against, the first run
+	// fails with err
+	readFrom := func(articleRepo *ArticleRepo, err error) *sql.DB {
+		var db *sql.DB
+		articleRepo.read(func(reader *ArticleRepo) error {
+			db = reader.db
+			failure := err
+			err = nil
+			return failure
+		})
+		return db
+	}
+
+	assert.Equal(t, replica, readFrom(articleRepo, nil))
+	assert.Equal(t, primary, readFrom(articleRepo.Primary().(*ArticleRepo), nil))
+
+	// only transient errors take the replica out, the read is retried on the
+	// primary and so are the next reads
+	assert.Equal(t, replica, readFrom(articleRepo, sql.ErrNoRows))
+	assert.Equal(t, primary, readFrom(articleRepo, driver.ErrBadConn))
+	assert.Equal(t, primary, readFrom(articleRepo, nil))
+}
@@ -0,0 +1,256 @@
+package repo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/sirupsen/logrus"
+	"rest-article/database/model"
+	"rest-article/log"
+	"time"
+)
+
+// MySQL error numbers worth retrying, the statement may well succeed when run
+// again
+const (
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+	errServerGone      = 2006
+	errLostConnection  = 2013
+)
+
+// RetryPolicy controls how operations that fail with a transient error are
+// retried, the wait between attempts starts at Backoff and doubles.
+type RetryPolicy struct {
+	// Attempts is the number of times an operation is run, 1 or less runs it
+	// once
+	Attempts int
+	Backoff  time.Duration
+}
+
+// RetryRepo retries the idempotent operations of the repo it wraps when they
+// fail with a transient MySQL error, such as a deadlock or a lost connection.
+// Every other operation is passed through as is, as running it twice could
+// apply it twice.
+type RetryRepo struct {
+	Repo
+	ctx    context.Context
+	policy RetryPolicy
+	logger *logrus.Entry
+}
+
+// NewRetryRepo wraps repo so its idempotent operations are retried under policy
+func NewRetryRepo(ctx context.Context, repo Repo, policy RetryPolicy) Repo {
+	return &RetryRepo{
+		Repo:   repo,
+		ctx:    ctx,
+		policy: policy,
+		logger: log.NewLogger().WithContext(ctx).WithField("module", "repo"),
+	}
+}
+
+// Primary returns the primary of the wrapped repo, retried under the same
+// policy
+func (retryRepo *RetryRepo) Primary() Repo {
+	return &RetryRepo{
+		Repo:   retryRepo.Repo.Primary(),
+		ctx:    retryRepo.ctx,
+		policy: retryRepo.policy,
+		logger: retryRepo.logger,
+	}
+}
+
+// IsTransient reports whether err is a MySQL error that may not happen again
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errLockWaitTimeout, errDeadlock, errServerGone, errLostConnection:
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs operation until it succeeds, fails with an error that is not
+// transient or runs out of attempts
+func (retryRepo *RetryRepo) retry(name string, operation func() error) error {
+	backoff := retryRepo.policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || !IsTransient(err) || attempt >= retryRepo.policy.Attempts {
+			return err
+		}
+
+		retryRepo.logger.Warnf("%s failed on attempt %d of %d, retrying in %s because: %v",
+			name, attempt, retryRepo.policy.Attempts, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-retryRepo.ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (retryRepo *RetryRepo) GetArticleByID(id string) (article *model.Article, tags []*model.Tag, err error) {
+	err = retryRepo.retry("GetArticleByID", func() error {
+		article, tags, err = retryRepo.Repo.GetArticleByID(id)
+		return err
+	})
+	return article, tags, err
+}
+
+func (retryRepo *RetryRepo) GetAdminArticleByID(id string) (article *model.Article, tags []*model.Tag, err error) {
+	err = retryRepo.retry("GetAdminArticleByID", func() error {
+		article, tags, err = retryRepo.Repo.GetAdminArticleByID(id)
+		return err
+	})
+	return article, tags, err
+}
+
+func (retryRepo *RetryRepo) ListArticles(filter model.ArticleFilter) (articles []*model.Article, err error) {
+	err = retryRepo.retry("ListArticles", func() error {
+		articles, err = retryRepo.Repo.ListArticles(filter)
+		return err
+	})
+	return articles, err
+}
+
+func (retryRepo *RetryRepo) GetArticleTags(articleIDs []int) (tags map[int][]string, err error) {
+	err = retryRepo.retry("GetArticleTags", func() error {
+		tags, err = retryRepo.Repo.GetArticleTags(articleIDs)
+		return err
+	})
+	return tags, err
+}
+
+func (retryRepo *RetryRepo) CountTagForDateName(name string, date time.Time) (count int, err error) {
+	err = retryRepo.retry("CountTagForDateName", func() error {
+		count, err = retryRepo.Repo.CountTagForDateName(name, date)
+		return err
+	})
+	return count, err
+}
+
+func (retryRepo *RetryRepo) GetRelatedTagForDateAndName(name string, date time.Time) (tags []string, err error) {
+	err = retryRepo.retry("GetRelatedTagForDateAndName", func() error {
+		tags, err = retryRepo.Repo.GetRelatedTagForDateAndName(name, date)
+		return err
+	})
+	return tags, err
+}
+
+func (retryRepo *RetryRepo) GetArticleIDForDateAndTag(name string, date time.Time) (ids []string, err error) {
+	err = retryRepo.retry("GetArticleIDForDateAndTag", func() error {
+		ids, err = retryRepo.Repo.GetArticleIDForDateAndTag(name, date)
+		return err
+	})
+	return ids, err
+}
+
+func (retryRepo *RetryRepo) GetTagCounts() (counts []*model.TagCount, err error) {
+	err = retryRepo.retry("GetTagCounts", func() error {
+		counts, err = retryRepo.Repo.GetTagCounts()
+		return err
+	})
+	return counts, err
+}
+
+func (retryRepo *RetryRepo) GetDeletedArticles() (articles []*model.Article, err error) {
+	err = retryRepo.retry("GetDeletedArticles", func() error {
+		articles, err = retryRepo.Repo.GetDeletedArticles()
+		return err
+	})
+	return articles, err
+}
+
+// PurgeArticles is retried as purging the same articles again deletes nothing
+func (retryRepo *RetryRepo) PurgeArticles(deletedBefore time.Time) (count int, err error) {
+	err = retryRepo.retry("PurgeArticles", func() error {
+		count, err = retryRepo.Repo.PurgeArticles(deletedBefore)
+		return err
+	})
+	return count, err
+}
+
+func (retryRepo *RetryRepo) GetRevisions(articleID string) (revisions []*model.Revision, err error) {
+	err = retryRepo.retry("GetRevisions", func() error {
+		revisions, err = retryRepo.Repo.GetRevisions(articleID)
+		return err
+	})
+	return revisions, err
+}
+
+func (retryRepo *RetryRepo) GetRevision(articleID string, number int) (revision *model.Revision, err error) {
+	err = retryRepo.retry("GetRevision", func() error {
+		revision, err = retryRepo.Repo.GetRevision(articleID, number)
+		return err
+	})
+	return revision, err
+}
+
+func (retryRepo *RetryRepo) GetAuthors() (authors []*model.Author, err error) {
+	err = retryRepo.retry("GetAuthors", func() error {
+		authors, err = retryRepo.Repo.GetAuthors()
+		return err
+	})
+	return authors, err
+}
+
+func (retryRepo *RetryRepo) GetAuthorByID(id string) (author *model.Author, err error) {
+	err = retryRepo.retry("GetAuthorByID", func() error {
+		author, err = retryRepo.Repo.GetAuthorByID(id)
+		return err
+	})
+	return author, err
+}
+
+func (retryRepo *RetryRepo) GetArticleAuthors(articleIDs []int) (authors map[int][]*model.Author, err error) {
+	err = retryRepo.retry("GetArticleAuthors", func() error {
+		authors, err = retryRepo.Repo.GetArticleAuthors(articleIDs)
+		return err
+	})
+	return authors, err
+}
+
+// SetArticleAuthors is retried as it replaces the authors as a whole
+func (retryRepo *RetryRepo) SetArticleAuthors(articleID int, authorIDs []int) error {
+	return retryRepo.retry("SetArticleAuthors", func() error {
+		return retryRepo.Repo.SetArticleAuthors(articleID, authorIDs)
+	})
+}
+
+func (retryRepo *RetryRepo) DumpArticles(afterID int, limit int) (dumps []*model.ArticleDump, err error) {
+	err = retryRepo.retry("DumpArticles", func() error {
+		dumps, err = retryRepo.Repo.DumpArticles(afterID, limit)
+		return err
+	})
+	return dumps, err
+}
+
+func (retryRepo *RetryRepo) GetAPIKeys() (keys []*model.APIKey, err error) {
+	err = retryRepo.retry("GetAPIKeys", func() error {
+		keys, err = retryRepo.Repo.GetAPIKeys()
+		return err
+	})
+	return keys, err
+}
+
+func (retryRepo *RetryRepo) GetAPIKeyByHash(hash string) (key *model.APIKey, err error) {
+	err = retryRepo.retry("GetAPIKeyByHash", func() error {
+		key, err = retryRepo.Repo.GetAPIKeyByHash(hash)
+		return err
+	})
+	return key, err
+}
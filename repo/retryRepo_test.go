@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"rest-article/database/model"
+	"testing"
+	"time"
+)
+
+// flakyRepo fails GetArticleByID and CreateArticle with err until failures
+// runs out
+type flakyRepo struct {
+	ArticleRepoMock
+	failures int
+	calls    int
+	err      error
+}
+
+func (fr *flakyRepo) GetArticleByID(id string) (*model.Article, []*model.Tag, error) {
+	fr.calls++
+	if fr.calls <= fr.failures {
+		return nil, nil, fr.err
+	}
+	return fr.ArticleRepoMock.GetArticleByID(id)
+}
+
+func (fr *flakyRepo) CreateArticle(article model.Article, tags []string, author string) (*model.Article, []*model.Tag, error) {
+	fr.calls++
+	return nil, nil, fr.err
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}, true},
+		{&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, true},
+		{fmt.Errorf("query failed: %w", mysql.ErrInvalidConn), true},
+		{driver.ErrBadConn, true},
+		{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, false},
+		{sql.ErrNoRows, false},
+		{nil, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.transient, IsTransient(test.err), fmt.Sprint(test.err))
+	}
+}
+
+func TestRetryRepo(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+	tests := []struct {
+		name     string
+		failures int
+		err      error
+		calls    int
+		fails    bool
+	}{
+		{"recovers", 2, deadlock, 3, false},
+		{"runs out of attempts", 3, deadlock, 3, true},
+		{"not transient", 3, sql.ErrNoRows, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flaky := &flakyRepo{failures: test.failures, err: test.err}
+			retryRepo := NewRetryRepo(context.Background(), flaky, RetryPolicy{Attempts: 3, Backoff: time.Millisecond})
+
+			article, _, err := retryRepo.GetArticleByID("1")
+
+			assert.Equal(t, test.calls, flaky.calls)
+			if test.fails {
+				assert.Equal(t, test.err, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, 1, article.Id)
+		})
+	}
+}
+
+func TestRetryRepoLeavesWritesAlone(t *testing.T) {
+	flaky := &flakyRepo{err: &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}}
+	retryRepo := NewRetryRepo(context.Background(), flaky, RetryPolicy{Attempts: 3, Backoff: time.Millisecond})
+
+	_, _, err := retryRepo.CreateArticle(model.Article{Id: 4}, nil, "test")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, flaky.calls)
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"database/sql"
+	"rest-article/database/model"
+	"rest-article/field"
+)
+
+// GetTagCounts returns every tag with the number of non deleted articles
+// carrying it, the most used first
+func (articleRepo *ArticleRepo) GetTagCounts() (counts []*model.TagCount, err error) {
+	err = articleRepo.read(func(reader *ArticleRepo) error {
+		counts, err = reader.getTagCounts()
+		return err
+	})
+	return counts, err
+}
+
+func (articleRepo *ArticleRepo) getTagCounts() ([]*model.TagCount, error) {
+
+	rows, err := articleRepo.db.QueryContext(articleRepo.ctx,
+		"SELECT tags.id, tags.tag_title, COUNT(articles.id) "+
+			"FROM `svc-article`.tags "+
+			"LEFT JOIN `svc-article`.article_tags on article_tags.tag_id = tags.id "+
+			"LEFT JOIN `svc-article`.articles on articles.id = article_tags.article_id AND articles.deleted_at IS NULL "+
+			"GROUP BY tags.id, tags.tag_title "+
+			"ORDER BY COUNT(articles.id) DESC, tags.tag_title")
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("GetTagCounts", "QueryContext")).
+			Errorf("error selecting tags because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*model.TagCount
+	for rows.Next() {
+		var tag model.TagCount
+		if err := rows.Scan(&tag.Id, &tag.Name, &tag.Count); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("GetTagCounts", "Scan")).
+				Errorf("failed to read tags because %v", err)
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// MergeTags moves every article tagged from over to the tag into, which is
+// created when it does not exist yet, and removes the tag from. It returns the
+// number of articles moved, articles carrying both tags keep a single one.
+// Revisions keep the tags they were stored with. sql.ErrNoRows is returned
+// when there is no tag from.
+func (articleRepo *ArticleRepo) MergeTags(from, into string) (int, error) {
+
+	tx, err := articleRepo.db.BeginTx(articleRepo.ctx, nil)
+	if err != nil {
+		articleRepo.logger.Errorf("failed to start transaction because: %v", err)
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	var fromID int
+	err = tx.QueryRowContext(articleRepo.ctx,
+		"SELECT `id` FROM `svc-article`.tags WHERE `tag_title` = ? FOR UPDATE", from).Scan(&fromID)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("MergeTags", "QueryRowContext")).
+			Errorf("failed to find tag %s because %v", from, err)
+		return -1, err
+	}
+
+	var intoID int64
+	err = tx.QueryRowContext(articleRepo.ctx,
+		"SELECT `id` FROM `svc-article`.tags WHERE `tag_title` = ? FOR UPDATE", into).Scan(&intoID)
+	if err == sql.ErrNoRows {
+		result, err := tx.ExecContext(articleRepo.ctx,
+			"INSERT INTO `svc-article`.tags(tag_title) VALUES (?)", into)
+		if err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("MergeTags", "ExecContext")).
+				Errorf("failed to insert tag %s because %v", into, err)
+			return -1, err
+		}
+		if intoID, err = result.LastInsertId(); err != nil {
+			return -1, err
+		}
+	} else if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("MergeTags", "QueryRowContext")).
+			Errorf("failed to find tag %s because %v", into, err)
+		return -1, err
+	}
+
+	result, err := tx.ExecContext(articleRepo.ctx,
+		"UPDATE `svc-article`.article_tags SET `tag_id` = ? "+
+			"WHERE `tag_id` = ? AND `article_id` NOT IN "+
+			"(SELECT `article_id` FROM (SELECT `article_id` FROM `svc-article`.article_tags WHERE `tag_id` = ?) AS tagged)",
+		intoID, fromID, intoID)
+	if err != nil {
+		articleRepo.logger.
+			WithFields(field.ErrorFields("MergeTags", "ExecContext")).
+			Errorf("failed to move articles from tag %s to %s because %v", from, into, err)
+		return -1, err
+	}
+
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+
+	statements := []string{
+		"DELETE FROM `svc-article`.article_tags WHERE `tag_id` = ?",
+		"DELETE FROM `svc-article`.tags WHERE `id` = ?",
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(articleRepo.ctx, statement, fromID); err != nil {
+			articleRepo.logger.
+				WithFields(field.ErrorFields("MergeTags", "ExecContext")).
+				Errorf("failed to remove tag %s because %v", from, err)
+			return -1, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		articleRepo.logger.Errorf("error failed to commit transaction: %v", err)
+		return -1, err
+	}
+
+	return int(moved), nil
+}
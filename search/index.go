@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"rest-article/database/model"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Index type constants
+const (
+	IndexTypeMySQL  = "mysql"
+	IndexTypeMemory = "memory"
+)
+
+// DefaultLimit is the number of results returned when a query does not set one
+const DefaultLimit = 10
+
+// Query describes a full-text search with its optional filters
+type Query struct {
+	Text  string
+	Tag   string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// Result is a single ranked match for a Query
+type Result struct {
+	Id      int
+	Title   string
+	Date    time.Time
+	Score   float64
+	Snippet string
+}
+
+// Index is a searchable view over articles. Implementations must be kept in
+// sync with every write made through repo.Repo.
+type Index interface {
+	Index(article model.Article, tags []string) error
+	Remove(id int) error
+	Search(query Query) ([]*Result, error)
+}
+
+// NewIndex returns the index implementation for the given database type, MySQL
+// uses the FULLTEXT index on the articles table and anything else falls back to
+// the in-process inverted index.
+func NewIndex(ctx context.Context, indexType string, db *sql.DB) Index {
+	if indexType == IndexTypeMySQL && db != nil {
+		return NewMySQLIndex(ctx, db)
+	}
+	return NewMemoryIndex()
+}
+
+// Terms splits text into lower cased search terms
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func (query Query) limit() int {
+	if query.Limit <= 0 {
+		return DefaultLimit
+	}
+	return query.Limit
+}
+
+func (query Query) matchesDate(date time.Time) bool {
+	if !query.From.IsZero() && date.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && date.After(query.To) {
+		return false
+	}
+	return true
+}
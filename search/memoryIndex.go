@@ -0,0 +1,136 @@
+package search
+
+import (
+	"math"
+	"rest-article/database/model"
+	"sort"
+	"sync"
+)
+
+// titleWeight boosts matches in the title over matches in the body
+const titleWeight = 2
+
+type document struct {
+	article model.Article
+	tags    map[string]bool
+	terms   map[string]int
+}
+
+// MemoryIndex is an in-process inverted index used when the database has no
+// full-text support of its own.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[int]*document
+	postings map[string]map[int]bool
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]bool),
+	}
+}
+
+func (index *MemoryIndex) Index(article model.Article, tags []string) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.remove(article.Id)
+
+	doc := &document{
+		article: article,
+		tags:    make(map[string]bool),
+		terms:   make(map[string]int),
+	}
+	for _, tag := range tags {
+		doc.tags[tag] = true
+	}
+	for _, term := range Terms(article.Title) {
+		doc.terms[term] += titleWeight
+	}
+	for _, term := range Terms(article.Body) {
+		doc.terms[term]++
+	}
+
+	for term := range doc.terms {
+		if index.postings[term] == nil {
+			index.postings[term] = make(map[int]bool)
+		}
+		index.postings[term][article.Id] = true
+	}
+	index.docs[article.Id] = doc
+
+	return nil
+}
+
+func (index *MemoryIndex) Remove(id int) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.remove(id)
+	return nil
+}
+
+func (index *MemoryIndex) remove(id int) {
+	doc, ok := index.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	delete(index.docs, id)
+}
+
+func (index *MemoryIndex) Search(query Query) ([]*Result, error) {
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	terms := Terms(query.Text)
+	scores := make(map[int]float64)
+	for _, term := range terms {
+		postings := index.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(index.docs))/float64(len(postings)))
+		for id := range postings {
+			doc := index.docs[id]
+			if query.Tag != "" && !doc.tags[query.Tag] {
+				continue
+			}
+			if !query.matchesDate(doc.article.Date) {
+				continue
+			}
+			scores[id] += float64(doc.terms[term]) * idf
+		}
+	}
+
+	var results []*Result
+	for id, score := range scores {
+		article := index.docs[id].article
+		results = append(results, &Result{
+			Id:      article.Id,
+			Title:   article.Title,
+			Date:    article.Date,
+			Score:   score,
+			Snippet: Snippet(article.Body, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+
+	if len(results) > query.limit() {
+		results = results[:query.limit()]
+	}
+
+	return results, nil
+}
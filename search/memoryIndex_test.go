@@ -0,0 +1,68 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"rest-article/database/model"
+	"testing"
+	"time"
+)
+
+func newTestIndex() *MemoryIndex {
+	index := NewMemoryIndex()
+	_ = index.Index(model.Article{
+		Id:    1,
+		Title: "Science of sleep",
+		Date:  time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC),
+		Body:  "Sleep matters for health.",
+	}, []string{"science", "health"})
+	_ = index.Index(model.Article{
+		Id:    2,
+		Title: "Marathon training",
+		Date:  time.Date(2020, 2, 10, 0, 0, 0, 0, time.UTC),
+		Body:  "Good sleep helps recovery after long runs.",
+	}, []string{"fitness"})
+	return index
+}
+
+func TestMemoryIndexRanksTitleMatchesFirst(t *testing.T) {
+	results, err := newTestIndex().Search(Query{Text: "sleep"})
+
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 1, results[0].Id)
+	assert.Equal(t, 2, results[1].Id)
+}
+
+func TestMemoryIndexFilters(t *testing.T) {
+	index := newTestIndex()
+
+	results, _ := index.Search(Query{Text: "sleep", Tag: "fitness"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Id)
+
+	results, _ = index.Search(Query{Text: "sleep", To: time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)})
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Id)
+}
+
+func TestMemoryIndexRemove(t *testing.T) {
+	index := newTestIndex()
+	_ = index.Remove(1)
+
+	results, _ := index.Search(Query{Text: "sleep"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Id)
+}
+
+func TestSnippetHighlightsTerms(t *testing.T) {
+	snippet := Snippet("Good sleep helps recovery.", []string{"sleep"})
+
+	assert.Equal(t, "Good <mark>sleep</mark> helps recovery.", snippet)
+}
+
+func TestSnippetEscapesText(t *testing.T) {
+	snippet := Snippet(`<script>alert("sleep")</script> & sleep`, []string{"sleep"})
+
+	assert.Equal(t, "&lt;script&gt;alert(&#34;<mark>sleep</mark>&#34;)&lt;/script&gt; &amp; <mark>sleep</mark>", snippet)
+	assert.Equal(t, "a &lt;b&gt;", Snippet("a <b>", nil))
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"rest-article/database/model"
+	"rest-article/field"
+	"rest-article/log"
+	"strings"
+)
+
+// MySQLIndex searches the FULLTEXT index on `svc-article`.articles. MySQL
+// maintains the index itself on every write so Index and Remove do nothing.
+type MySQLIndex struct {
+	ctx    context.Context
+	db     *sql.DB
+	logger *logrus.Entry
+}
+
+func NewMySQLIndex(ctx context.Context, db *sql.DB) *MySQLIndex {
+	return &MySQLIndex{
+		ctx:    ctx,
+		db:     db,
+		logger: log.NewLogger().WithContext(ctx).WithField("module", "search"),
+	}
+}
+
+func (index *MySQLIndex) Index(article model.Article, tags []string) error {
+	return nil
+}
+
+func (index *MySQLIndex) Remove(id int) error {
+	return nil
+}
+
+func (index *MySQLIndex) Search(query Query) ([]*Result, error) {
+
+	conditions := []string{
+		"MATCH(articles.title, articles.body) AGAINST(? IN NATURAL LANGUAGE MODE)",
+		"articles.deleted_at IS NULL",
+		"articles.status = '" + model.StatusPublished + "'",
+	}
+	args := []interface{}{query.Text, query.Text}
+
+	if query.Tag != "" {
+		conditions = append(conditions, "EXISTS ("+
+			"SELECT 1 FROM `svc-article`.article_tags "+
+			"INNER JOIN `svc-article`.tags on tags.id = article_tags.tag_id "+
+			"WHERE article_tags.article_id = articles.id AND tags.tag_title = ?)")
+		args = append(args, query.Tag)
+	}
+
+	if !query.From.IsZero() {
+		conditions = append(conditions, "articles.date >= ?")
+		args = append(args, query.From.Format("2006-01-02"))
+	}
+
+	if !query.To.IsZero() {
+		conditions = append(conditions, "articles.date <= ?")
+		args = append(args, query.To.Format("2006-01-02"))
+	}
+
+	args = append(args, query.limit())
+
+	rows, err := index.db.QueryContext(index.ctx,
+		"SELECT articles.id, articles.title, articles.date, articles.body, "+
+			"MATCH(articles.title, articles.body) AGAINST(? IN NATURAL LANGUAGE MODE) AS score "+
+			"FROM `svc-article`.articles "+
+			"WHERE "+strings.Join(conditions, " AND ")+" "+
+			"ORDER BY score DESC, articles.id "+
+			"LIMIT ?", args...)
+	if err != nil {
+		index.logger.
+			WithFields(field.ErrorFields("Search", "QueryContext")).
+			Errorf("search query failed because: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := Terms(query.Text)
+	var results []*Result
+	for rows.Next() {
+		var result Result
+		var body string
+		err := rows.Scan(&result.Id, &result.Title, &result.Date, &body, &result.Score)
+		if err != nil {
+			index.logger.
+				WithFields(field.ErrorFields("Search", "Scan")).
+				Errorf("failed to read search result because: %v", err)
+			return nil, err
+		}
+		result.Snippet = Snippet(body, terms)
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Highlight markers wrapped around matched terms in a snippet
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+const snippetWidth = 160
+
+// Snippet returns a window of text around the first matched term with every
+// matched term wrapped in the highlight markers. The text is HTML escaped so
+// the markers are the only markup in the snippet.
+func Snippet(text string, terms []string) string {
+	if len(terms) == 0 || text == "" {
+		return html.EscapeString(truncate(text, 0, snippetWidth))
+	}
+
+	termSet := make(map[string]bool)
+	for _, term := range terms {
+		termSet[term] = true
+	}
+
+	words := wordSpans(text)
+	start := 0
+	for _, word := range words {
+		if termSet[strings.ToLower(text[word[0]:word[1]])] {
+			start = word[0] - snippetWidth/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := start + snippetWidth
+	if end > len(text) {
+		end = len(text)
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("...")
+	}
+	last := start
+	for _, word := range words {
+		if word[0] < start || word[1] > end {
+			continue
+		}
+		if !termSet[strings.ToLower(text[word[0]:word[1]])] {
+			continue
+		}
+		builder.WriteString(html.EscapeString(text[last:word[0]]))
+		builder.WriteString(HighlightStart)
+		builder.WriteString(html.EscapeString(text[word[0]:word[1]]))
+		builder.WriteString(HighlightEnd)
+		last = word[1]
+	}
+	builder.WriteString(html.EscapeString(text[last:end]))
+	if end < len(text) {
+		builder.WriteString("...")
+	}
+
+	return builder.String()
+}
+
+func truncate(text string, start, width int) string {
+	if len(text) <= start+width {
+		return text[start:]
+	}
+	end := start + width
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	return text[start:end] + "..."
+}
+
+// wordSpans returns the byte offsets of every word in text
+func wordSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsNumber(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(text)})
+	}
+	return spans
+}